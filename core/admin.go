@@ -0,0 +1,56 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/locale"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) registerAdminRoutes(group *gin.RouterGroup) {
+	group.GET("/teams", s.handleGetTeams)
+	group.PUT("/teams/:idx", s.handlePutTeam)
+	group.GET("/queue", s.handleGetQueue)
+	group.GET("/quota", s.handleGetQuota)
+}
+
+func (s *Server) handleGetTeams(c *gin.Context) {
+	if s.matchOptimizer == nil {
+		c.JSON(http.StatusOK, gin.H{"teams": s.config.Matching.Teams})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"teams": s.matchOptimizer.getTeams()})
+}
+
+func (s *Server) handlePutTeam(c *gin.Context) {
+	if s.matchOptimizer == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": locale.T("マッチオプティマイザが有効ではありません")})
+		return
+	}
+	idx, err := strconv.Atoi(c.Param("idx"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": locale.T("チームのインデックスが不正です")})
+		return
+	}
+	var body struct {
+		Team string `json:"team"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": locale.T(err.Error())})
+		return
+	}
+	if err := s.matchOptimizer.replaceTeam(idx, body.Team); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": locale.T(err.Error())})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"teams": s.matchOptimizer.getTeams()})
+}
+
+func (s *Server) handleGetQueue(c *gin.Context) {
+	c.JSON(http.StatusOK, s.gameQueue.Status())
+}
+
+func (s *Server) handleGetQuota(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"teams": s.teamQuota.Status()})
+}
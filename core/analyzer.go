@@ -1,118 +1,79 @@
 package core
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"slices"
 	"strings"
 
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 )
 
-func Analyzer(config model.Config) {
+func Analyzer(config model.Config) *Report {
+	report := NewReport()
+
 	data, err := os.ReadFile(config.Matching.OutputPath)
 	if err != nil {
 		slog.Warn("マッチオプティマイザの読み込みに失敗しました", "error", err)
-		return
-	}
-	var mo MatchOptimizer
-	if err := json.Unmarshal(data, &mo); err != nil {
-		slog.Error("マッチオプティマイザのパースに失敗しました", "error", err)
-		return
-	}
-
-	slog.Info("マッチオプティマイザの統計データを分析します")
-	for idx, team := range mo.IdxTeamMap {
-		slog.Info("登録済みチームを取得しました", "idx", idx, "team", team)
-
-		scheduledRoles := make(map[model.Role]int)
-		for _, match := range mo.ScheduledMatches {
-			for role, idxs := range match.RoleIdxs {
-				for _, i := range idxs {
-					if idx == i {
-						scheduledRoles[role]++
-					}
-				}
-			}
-		}
-		sum := 0
-		for _, count := range scheduledRoles {
-			sum += count
-		}
-		slog.Info("スケジュールされた役職を取得しました", "idx", idx, "roles", scheduledRoles, "sum", sum)
-
-		endedRoles := make(map[model.Role]int)
-		for _, match := range mo.EndedMatches {
-			for role, idxs := range match {
-				for _, i := range idxs {
-					if idx == i {
-						endedRoles[role]++
-					}
-				}
-			}
-		}
-		sum = 0
-		for _, count := range endedRoles {
-			sum += count
+	} else {
+		var mo MatchOptimizer
+		if err := json.Unmarshal(data, &mo); err != nil {
+			slog.Error("マッチオプティマイザのパースに失敗しました", "error", err)
+		} else {
+			analyzeMatchOptimizer(&mo, report)
 		}
-		slog.Info("終了した役職を取得しました", "idx", idx, "roles", endedRoles, "sum", sum)
 	}
 
 	if config.GameLogger.Enable {
 		slog.Info("ログサービスの統計データを分析します")
 
-		filePaths, err := filepath.Glob(filepath.Join(config.GameLogger.OutputDir, "*.log"))
-		if err != nil {
-			slog.Warn("ファイルの取得に失敗しました", "error", err)
-		}
-
 		counts := make(map[string]map[model.Role]*Count)
 
-		for _, filePath := range filePaths {
-			file, err := os.Open(filePath)
-			if err != nil {
-				slog.Warn("ファイルの読み込みに失敗しました", "error", err)
-			}
-			defer file.Close()
-
+		err := util.WalkLogFiles(config.GameLogger.OutputDir, config.LogArchive.OutputDir, util.ArchivePrefixGame, ".log", func(filePath string, file io.Reader) error {
 			teamsRole := make(map[string]model.Role)
 			errorTeams := []string{}
 			var winSide *model.Team
 
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := scanner.Text()
-				values := strings.Split(line, ",")
-				if (len(values) == 6 || len(values) == 7) && values[1] == "status" {
-					if values[0] == "0" {
-						team := strings.TrimRight(values[5], "1234567890")
-						role := model.RoleFromString(values[3])
-						teamsRole[team] = role
-					} else {
-						team := strings.TrimRight(values[5], "1234567890")
-						status := values[4]
-						if status == "" {
-							errorTeams = append(errorTeams, team)
-						}
-					}
+			reader, err := gamelog.NewReader(file)
+			if err != nil {
+				slog.Warn("ゲームログの読み込みに失敗しました", "file", filePath, "error", err)
+				return nil
+			}
+			for {
+				event, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					break
 				}
-				if len(values) == 5 && values[1] == "result" {
-					side := model.TeamFromString(values[4])
-					winSide = &side
+				if err != nil {
+					slog.Warn("ゲームログのパースに失敗しました", "file", filePath, "error", err)
+					break
+				}
+				switch e := event.(type) {
+				case gamelog.StatusEvent:
+					team := strings.TrimRight(e.OriginalName, "1234567890")
+					if e.Day == 0 {
+						teamsRole[team] = e.Role
+					} else if e.Status == "" {
+						errorTeams = append(errorTeams, team)
+					}
+				case gamelog.ResultEvent:
+					winSide = &e.WinSide
 				}
 			}
 
 			if len(teamsRole) == 0 {
 				slog.Warn("役職が取得できませんでした", "file", filePath)
-				continue
+				return nil
 			}
 
 			if winSide == nil {
 				slog.Warn("結果が取得できませんでした", "file", filePath)
-				continue
+				return nil
 			}
 
 			for team, role := range teamsRole {
@@ -141,6 +102,10 @@ func Analyzer(config model.Config) {
 					}
 				}
 			}
+			return nil
+		})
+		if err != nil {
+			slog.Warn("ファイルの取得に失敗しました", "error", err)
 		}
 
 		for team, roles := range counts {
@@ -155,6 +120,64 @@ func Analyzer(config model.Config) {
 			}
 			slog.Info("統計データを取得しました", "team", team, "win", global.Win, "lose", global.Lose, "error", global.Error, "none", global.None, "succeed", global.Succeed)
 		}
+		report.setCounts(counts)
+	}
+
+	if config.JSONLogger.Enable {
+		slog.Info("応答時間の統計データを分析します")
+
+		latencyAnalyzer := NewLatencyAnalyzer()
+		if err := util.WalkLogFiles(config.JSONLogger.OutputDir, config.LogArchive.OutputDir, util.ArchivePrefixJSON, ".json", latencyAnalyzer.Add); err != nil {
+			slog.Warn("ファイルの取得に失敗しました", "error", err)
+		}
+		report.Latency = latencyAnalyzer.Reports()
+		for _, latency := range report.Latency {
+			for _, request := range latency.Requests {
+				slog.Info("応答時間の統計データを取得しました", "team", latency.Team, "request", request.Request, "count", request.Count, "mean", request.Mean, "p90", request.P90, "max", request.Max, "timeouts", request.Timeouts, "name_fallbacks", request.NameFallbacks)
+			}
+		}
+	}
+	return report
+}
+
+func analyzeMatchOptimizer(mo *MatchOptimizer, report *Report) {
+	slog.Info("マッチオプティマイザの統計データを分析します")
+	for idx, team := range mo.IdxTeamMap {
+		slog.Info("登録済みチームを取得しました", "idx", idx, "team", team)
+
+		scheduledRoles := make(map[model.Role]int)
+		for _, match := range mo.ScheduledMatches {
+			for role, idxs := range match.RoleIdxs {
+				for _, i := range idxs {
+					if idx == i {
+						scheduledRoles[role]++
+					}
+				}
+			}
+		}
+		sum := 0
+		for _, count := range scheduledRoles {
+			sum += count
+		}
+		slog.Info("スケジュールされた役職を取得しました", "idx", idx, "roles", scheduledRoles, "sum", sum)
+
+		endedRoles := make(map[model.Role]int)
+		for _, match := range mo.EndedMatches {
+			for role, idxs := range match {
+				for _, i := range idxs {
+					if idx == i {
+						endedRoles[role]++
+					}
+				}
+			}
+		}
+		sum = 0
+		for _, count := range endedRoles {
+			sum += count
+		}
+		slog.Info("終了した役職を取得しました", "idx", idx, "roles", endedRoles, "sum", sum)
+
+		report.addCoverage(idx, team, scheduledRoles, endedRoles)
 	}
 }
 
@@ -203,3 +226,11 @@ type Count struct {
 	Lose    int
 	Error   int
 }
+
+func (c *Count) add(other Count) {
+	c.Succeed += other.Succeed
+	c.None += other.None
+	c.Win += other.Win
+	c.Lose += other.Lose
+	c.Error += other.Error
+}
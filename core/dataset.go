@@ -0,0 +1,208 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+const (
+	DO_WIN  = "win"
+	DO_LOSE = "lose"
+	DO_NONE = "none"
+)
+
+type DatasetOptions struct {
+	Teams     []string
+	Roles     []string
+	Outcomes  []string
+	Anonymize bool
+}
+
+type DatasetRecord struct {
+	GameID   string            `json:"game_id"`
+	Agent    string            `json:"agent"`
+	Team     string            `json:"team"`
+	Name     string            `json:"name"`
+	Role     string            `json:"role"`
+	Roles    map[string]string `json:"roles"`
+	WinSide  string            `json:"win_side"`
+	Outcome  string            `json:"outcome"`
+	Packet   json.RawMessage   `json:"packet"`
+	Response string            `json:"response,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+type DatasetExporter struct {
+	options DatasetOptions
+	encoder *json.Encoder
+	aliases map[string]string
+	Games   int
+	Records int
+}
+
+func NewDatasetExporter(w io.Writer, options DatasetOptions) *DatasetExporter {
+	return &DatasetExporter{
+		options: options,
+		encoder: json.NewEncoder(w),
+		aliases: make(map[string]string),
+	}
+}
+
+func (de *DatasetExporter) Add(filePath string, r io.Reader) error {
+	var log ReplayLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		slog.Warn("JSONログのパースに失敗しました", "file", filePath, "error", err)
+		return nil
+	}
+
+	gameNames := make([]string, len(log.Agents))
+	initializeCount := 0
+	for _, entry := range log.Entries {
+		var packet struct {
+			Request string `json:"request"`
+		}
+		if json.Unmarshal([]byte(entry.Request), &packet) != nil || packet.Request != model.R_INITIALIZE.Type {
+			continue
+		}
+		if initializeCount < len(gameNames) {
+			gameNames[initializeCount] = entry.Agent
+		}
+		initializeCount++
+	}
+	agents := make(map[string]int)
+	roles := make(map[string]string)
+	for i, agent := range log.Agents {
+		if agent.GameName != "" {
+			gameNames[i] = agent.GameName
+		}
+		if gameNames[i] == "" {
+			continue
+		}
+		agents[gameNames[i]] = i
+		roles[gameNames[i]] = agent.Role
+	}
+	if len(agents) != len(log.Agents) {
+		slog.Warn("エージェントのゲーム内名を特定できませんでした", "file", filePath)
+		return nil
+	}
+
+	exported := false
+	for _, entry := range log.Entries {
+		i, exists := agents[entry.Agent]
+		if !exists || !json.Valid([]byte(entry.Request)) {
+			continue
+		}
+		agent := log.Agents[i]
+		outcome := datasetOutcome(model.RoleFromString(agent.Role), model.Team(log.WinSide))
+		if !de.match(agent.Team, agent.Role, outcome) {
+			continue
+		}
+		record := DatasetRecord{
+			GameID:   log.GameID,
+			Agent:    entry.Agent,
+			Team:     agent.Team,
+			Name:     agent.Name,
+			Role:     agent.Role,
+			Roles:    roles,
+			WinSide:  log.WinSide,
+			Outcome:  outcome,
+			Packet:   json.RawMessage(entry.Request),
+			Response: entry.Response,
+			Error:    entry.Error,
+		}
+		if de.options.Anonymize {
+			record.Team = de.alias(agent.Team)
+			record.Name = record.Team
+			if suffix, found := strings.CutPrefix(agent.Name, agent.Team); found {
+				record.Name += suffix
+			}
+			if entry.Response == agent.Name || entry.Response == agent.Team {
+				record.Response = record.Name
+			}
+		}
+		if err := de.encoder.Encode(record); err != nil {
+			return err
+		}
+		de.Records++
+		exported = true
+	}
+	if exported {
+		de.Games++
+	}
+	return nil
+}
+
+func (de *DatasetExporter) match(team string, role string, outcome string) bool {
+	if len(de.options.Teams) > 0 && !slices.Contains(de.options.Teams, team) {
+		return false
+	}
+	if len(de.options.Roles) > 0 && !slices.Contains(de.options.Roles, role) {
+		return false
+	}
+	if len(de.options.Outcomes) > 0 && !slices.Contains(de.options.Outcomes, outcome) {
+		return false
+	}
+	return true
+}
+
+func (de *DatasetExporter) alias(team string) string {
+	if alias, exists := de.aliases[team]; exists {
+		return alias
+	}
+	alias := fmt.Sprintf("team%02d", len(de.aliases)+1)
+	de.aliases[team] = alias
+	return alias
+}
+
+func datasetOutcome(role model.Role, winSide model.Team) string {
+	if winSide != model.T_VILLAGER && winSide != model.T_WEREWOLF {
+		return DO_NONE
+	}
+	if role.Team == winSide {
+		return DO_WIN
+	}
+	return DO_LOSE
+}
+
+func ExportDataset(config model.Config, path string, options DatasetOptions) error {
+	if !config.JSONLogger.Enable {
+		return errors.New("JSONログが無効になっています")
+	}
+	for _, outcome := range options.Outcomes {
+		if outcome != DO_WIN && outcome != DO_LOSE && outcome != DO_NONE {
+			return errors.New("不明な勝敗です: " + outcome)
+		}
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	exporter := NewDatasetExporter(writer, options)
+	if err := util.WalkLogFiles(config.JSONLogger.OutputDir, config.LogArchive.OutputDir, util.ArchivePrefixJSON, ".json", exporter.Add); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	slog.Info("データセットを出力しました", "path", path, "games", exporter.Games, "records", exporter.Records)
+	return nil
+}
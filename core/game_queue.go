@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+var ErrGameQueueFull = errors.New("ゲームキューが上限に達しています")
+
+type GameQueue struct {
+	maxGames        int
+	maxGamesPerTeam int
+	maxQueued       int
+	mu              sync.Mutex
+	queue           []*QueuedGame
+	running         int
+	runningTeams    map[string]int
+	onRelease       func()
+}
+
+type QueuedGame struct {
+	game     *logic.Game
+	teams    []string
+	run      func()
+	queuedAt time.Time
+}
+
+type GameQueueStatus struct {
+	MaxGames        int               `json:"max_games"`
+	MaxGamesPerTeam int               `json:"max_games_per_team"`
+	MaxQueued       int               `json:"max_queued"`
+	Running         int               `json:"running"`
+	RunningTeams    map[string]int    `json:"running_teams"`
+	Queued          []QueuedGameState `json:"queued"`
+}
+
+type QueuedGameState struct {
+	ID       string    `json:"id"`
+	Teams    []string  `json:"teams"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+func NewGameQueue(config model.Config) *GameQueue {
+	return &GameQueue{
+		maxGames:        config.Server.Concurrency.MaxGames,
+		maxGamesPerTeam: config.Server.Concurrency.MaxGamesPerTeam,
+		maxQueued:       config.Server.Concurrency.MaxQueuedGames,
+		queue:           []*QueuedGame{},
+		runningTeams:    make(map[string]int),
+	}
+}
+
+func (gq *GameQueue) Enqueue(game *logic.Game, run func()) error {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+	teams := game.GetTeamNames()
+	if gq.isFull() && !gq.canStart(teams) {
+		return ErrGameQueueFull
+	}
+	gq.queue = append(gq.queue, &QueuedGame{
+		game:     game,
+		teams:    teams,
+		run:      run,
+		queuedAt: time.Now(),
+	})
+	slog.Info("ゲームをキューに追加しました", "id", game.GetID(), "length", len(gq.queue))
+	gq.dispatch()
+	return nil
+}
+
+func (gq *GameQueue) IsFull() bool {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+	return gq.isFull()
+}
+
+func (gq *GameQueue) isFull() bool {
+	return gq.maxQueued > 0 && len(gq.queue) >= gq.maxQueued
+}
+
+func (gq *GameQueue) dispatch() {
+	for i := 0; i < len(gq.queue); {
+		queued := gq.queue[i]
+		if !gq.canStart(queued.teams) {
+			i++
+			continue
+		}
+		gq.queue = slices.Delete(gq.queue, i, i+1)
+		gq.running++
+		for _, team := range queued.teams {
+			gq.runningTeams[team]++
+		}
+		slog.Info("キューからゲームを開始します", "id", queued.game.GetID(), "running", gq.running, "length", len(gq.queue))
+		go func() {
+			queued.run()
+			gq.release(queued.teams)
+		}()
+	}
+}
+
+func (gq *GameQueue) canStart(teams []string) bool {
+	if gq.maxGames > 0 && gq.running >= gq.maxGames {
+		return false
+	}
+	if gq.maxGamesPerTeam > 0 {
+		for _, team := range teams {
+			if gq.runningTeams[team] >= gq.maxGamesPerTeam {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (gq *GameQueue) OnRelease(fn func()) {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+	gq.onRelease = fn
+}
+
+func (gq *GameQueue) release(teams []string) {
+	gq.mu.Lock()
+	gq.running--
+	for _, team := range teams {
+		gq.runningTeams[team]--
+		if gq.runningTeams[team] <= 0 {
+			delete(gq.runningTeams, team)
+		}
+	}
+	gq.dispatch()
+	onRelease := gq.onRelease
+	gq.mu.Unlock()
+	if onRelease != nil {
+		onRelease()
+	}
+}
+
+func (gq *GameQueue) Status() GameQueueStatus {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+	status := GameQueueStatus{
+		MaxGames:        gq.maxGames,
+		MaxGamesPerTeam: gq.maxGamesPerTeam,
+		MaxQueued:       gq.maxQueued,
+		Running:         gq.running,
+		RunningTeams:    maps.Clone(gq.runningTeams),
+		Queued:          make([]QueuedGameState, 0, len(gq.queue)),
+	}
+	for _, queued := range gq.queue {
+		status.Queued = append(status.Queued, QueuedGameState{
+			ID:       queued.game.GetID(),
+			Teams:    queued.teams,
+			QueuedAt: queued.queuedAt,
+		})
+	}
+	return status
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+const slowestRequestCount = 10
+
+type LatencyReport struct {
+	Team          string           `json:"team"`
+	Requests      []RequestLatency `json:"requests"`
+	Timeouts      int              `json:"timeouts"`
+	NameFallbacks int              `json:"name_fallbacks"`
+	Slowest       []SlowRequest    `json:"slowest"`
+}
+
+type RequestLatency struct {
+	Request       string  `json:"request"`
+	Count         int     `json:"count"`
+	Mean          float64 `json:"mean_ms"`
+	P50           int64   `json:"p50_ms"`
+	P90           int64   `json:"p90_ms"`
+	P99           int64   `json:"p99_ms"`
+	Max           int64   `json:"max_ms"`
+	Timeouts      int     `json:"timeouts"`
+	NameFallbacks int     `json:"name_fallbacks"`
+}
+
+type SlowRequest struct {
+	GameID   string `json:"game_id"`
+	Agent    string `json:"agent"`
+	Request  string `json:"request"`
+	Day      int    `json:"day"`
+	Duration int64  `json:"duration_ms"`
+	Error    string `json:"error,omitempty"`
+}
+
+type latencyLog struct {
+	GameID string `json:"game_id"`
+	Agents []struct {
+		Team     string `json:"team"`
+		Name     string `json:"name"`
+		GameName string `json:"game_name"`
+	} `json:"agents"`
+	Entries []struct {
+		Agent             string `json:"agent"`
+		Request           string `json:"request"`
+		Error             string `json:"error"`
+		RequestTimestamp  *int64 `json:"request_timestamp"`
+		ResponseTimestamp int64  `json:"response_timestamp"`
+	} `json:"entries"`
+}
+
+type latencySample struct {
+	durations     []int64
+	timeouts      int
+	nameFallbacks int
+}
+
+type LatencyAnalyzer struct {
+	samples map[string]map[string]*latencySample
+	slowest map[string][]SlowRequest
+}
+
+func NewLatencyAnalyzer() *LatencyAnalyzer {
+	return &LatencyAnalyzer{
+		samples: make(map[string]map[string]*latencySample),
+		slowest: make(map[string][]SlowRequest),
+	}
+}
+
+func (la *LatencyAnalyzer) Add(filePath string, r io.Reader) error {
+	var log latencyLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		slog.Warn("JSONログのパースに失敗しました", "file", filePath, "error", err)
+		return nil
+	}
+	gameNames := make([]string, len(log.Agents))
+	initializeCount := 0
+	for _, entry := range log.Entries {
+		var packet struct {
+			Request string `json:"request"`
+		}
+		if json.Unmarshal([]byte(entry.Request), &packet) != nil || packet.Request != model.R_INITIALIZE.Type {
+			continue
+		}
+		if initializeCount < len(gameNames) {
+			gameNames[initializeCount] = entry.Agent
+		}
+		initializeCount++
+	}
+	teams := make(map[string]string)
+	for i, agent := range log.Agents {
+		if agent.GameName != "" {
+			gameNames[i] = agent.GameName
+		}
+		if gameNames[i] != "" {
+			teams[gameNames[i]] = agent.Team
+		}
+	}
+	for _, entry := range log.Entries {
+		team, exists := teams[entry.Agent]
+		if !exists {
+			continue
+		}
+		var packet struct {
+			Request string `json:"request"`
+			Info    *struct {
+				Day int `json:"day"`
+			} `json:"info"`
+		}
+		if err := json.Unmarshal([]byte(entry.Request), &packet); err != nil || packet.Request == "" {
+			continue
+		}
+		if _, exists := la.samples[team]; !exists {
+			la.samples[team] = make(map[string]*latencySample)
+		}
+		sample, exists := la.samples[team][packet.Request]
+		if !exists {
+			sample = &latencySample{}
+			la.samples[team][packet.Request] = sample
+		}
+		switch entry.Error {
+		case model.ErrResponseTimeout.Error():
+			sample.timeouts++
+			sample.nameFallbacks++
+		case model.ErrNameResponseTimeout.Error():
+			sample.timeouts++
+		}
+		if entry.RequestTimestamp == nil {
+			continue
+		}
+		duration := entry.ResponseTimestamp - *entry.RequestTimestamp
+		sample.durations = append(sample.durations, duration)
+
+		slow := SlowRequest{
+			GameID:   log.GameID,
+			Agent:    entry.Agent,
+			Request:  packet.Request,
+			Duration: duration,
+			Error:    entry.Error,
+		}
+		if packet.Info != nil {
+			slow.Day = packet.Info.Day
+		}
+		la.slowest[team] = append(la.slowest[team], slow)
+		slices.SortStableFunc(la.slowest[team], func(a, b SlowRequest) int {
+			return cmp.Compare(b.Duration, a.Duration)
+		})
+		if len(la.slowest[team]) > slowestRequestCount {
+			la.slowest[team] = la.slowest[team][:slowestRequestCount]
+		}
+	}
+	return nil
+}
+
+func (la *LatencyAnalyzer) Reports() []LatencyReport {
+	reports := make([]LatencyReport, 0, len(la.samples))
+	for team, requests := range la.samples {
+		report := LatencyReport{
+			Team:     team,
+			Requests: make([]RequestLatency, 0, len(requests)),
+			Slowest:  la.slowest[team],
+		}
+		if report.Slowest == nil {
+			report.Slowest = []SlowRequest{}
+		}
+		for request, sample := range requests {
+			latency := RequestLatency{
+				Request:       request,
+				Count:         len(sample.durations),
+				Timeouts:      sample.timeouts,
+				NameFallbacks: sample.nameFallbacks,
+			}
+			if len(sample.durations) > 0 {
+				durations := slices.Clone(sample.durations)
+				slices.Sort(durations)
+				var sum int64
+				for _, duration := range durations {
+					sum += duration
+				}
+				latency.Mean = float64(sum) / float64(len(durations))
+				latency.P50 = percentile(durations, 0.5)
+				latency.P90 = percentile(durations, 0.9)
+				latency.P99 = percentile(durations, 0.99)
+				latency.Max = durations[len(durations)-1]
+			}
+			report.Timeouts += sample.timeouts
+			report.NameFallbacks += sample.nameFallbacks
+			report.Requests = append(report.Requests, latency)
+		}
+		slices.SortFunc(report.Requests, func(a, b RequestLatency) int {
+			return strings.Compare(a.Request, b.Request)
+		})
+		reports = append(reports, report)
+	}
+	slices.SortFunc(reports, func(a, b LatencyReport) int {
+		return strings.Compare(a.Team, b.Team)
+	})
+	return reports
+}
+
+func percentile(sorted []int64, p float64) int64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(idx, len(sorted)-1))]
+}
@@ -4,19 +4,30 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 )
 
+const (
+	defaultMaxSubstitutions = 1
+	maxRetryBackoff         = time.Hour
+)
+
 type MatchOptimizer struct {
 	mu               sync.RWMutex           `json:"-"`
 	outputPath       string                 `json:"-"`
+	maxRetries       int                    `json:"-"`
+	retryBackoff     time.Duration          `json:"-"`
+	substitute       bool                   `json:"-"`
+	maxSubstitutions int                    `json:"-"`
 	InfiniteLoop     bool                   `json:"infinite_loop"`
 	TeamCount        int                    `json:"team_count"`
 	GameCount        int                    `json:"game_count"`
@@ -61,8 +72,11 @@ func (mo *MatchOptimizer) UnmarshalJSON(data []byte) error {
 		RoleNumMap       map[string]int     `json:"role_num_map"`
 		EndedMatches     []map[string][]int `json:"ended_matches"`
 		ScheduledMatches []struct {
-			RoleIdxs map[string][]int `json:"role_idxs"`
-			Weight   float64          `json:"weight"`
+			RoleIdxs   map[string][]int     `json:"role_idxs"`
+			Weight     float64              `json:"weight"`
+			Retries    int                  `json:"retries"`
+			RetryAfter *time.Time           `json:"retry_after,omitempty"`
+			Failures   []model.MatchFailure `json:"failures,omitempty"`
 		} `json:"scheduled_matches"`
 	}{
 		Alias: (*Alias)(mo),
@@ -84,8 +98,11 @@ func (mo *MatchOptimizer) UnmarshalJSON(data []byte) error {
 	mo.ScheduledMatches = make([]model.MatchWeight, len(aux.ScheduledMatches))
 	for i, scheduledMatch := range aux.ScheduledMatches {
 		mo.ScheduledMatches[i] = model.MatchWeight{
-			RoleIdxs: make(map[model.Role][]int),
-			Weight:   scheduledMatch.Weight,
+			RoleIdxs:   make(map[model.Role][]int),
+			Weight:     scheduledMatch.Weight,
+			Retries:    scheduledMatch.Retries,
+			RetryAfter: scheduledMatch.RetryAfter,
+			Failures:   scheduledMatch.Failures,
 		}
 		for role, idxs := range scheduledMatch.RoleIdxs {
 			mo.ScheduledMatches[i].RoleIdxs[model.RoleFromString(role)] = idxs
@@ -105,7 +122,32 @@ func NewMatchOptimizer(config model.Config) (*MatchOptimizer, error) {
 		slog.Error("マッチオプティマイザのパースに失敗しました", "error", err)
 		return nil, err
 	}
-	mo.outputPath = config.Matching.OutputPath
+	mo.configure(config)
+	if len(config.Matching.Teams) > 0 && len(config.Matching.Teams) != mo.TeamCount {
+		slog.Error("登録チーム数が保存されたチーム数と一致しません", "teams", len(config.Matching.Teams), "team_count", mo.TeamCount)
+		return nil, errors.New("登録チーム数とチーム数が一致しません")
+	}
+	if mo.IdxTeamMap == nil {
+		mo.IdxTeamMap = map[int]string{}
+	}
+	for idx, team := range config.Matching.Teams {
+		current, exists := mo.IdxTeamMap[idx]
+		if exists && current == team {
+			continue
+		}
+		for i, t := range mo.IdxTeamMap {
+			if t == team && i != idx && (i >= len(config.Matching.Teams) || config.Matching.Teams[i] == team) {
+				slog.Error("登録チームが別のインデックスに割り当てられています", "team", team, "idx", idx, "registered_idx", i)
+				return nil, errors.New("登録チームが重複しています")
+			}
+		}
+		mo.IdxTeamMap[idx] = team
+		if exists {
+			slog.Warn("設定ファイルの登録チームでインデックスの割り当てを上書きしました", "team", team, "previous_team", current, "idx", idx)
+		} else {
+			slog.Info("登録チームを追加しました", "team", team, "idx", idx)
+		}
+	}
 	mo.save()
 	return &mo, nil
 }
@@ -116,18 +158,44 @@ func NewMatchOptimizerFromConfig(config model.Config) (*MatchOptimizer, error) {
 	if err != nil {
 		return nil, err
 	}
+	teamCount := config.Matching.TeamCount
+	if len(config.Matching.Teams) > 0 {
+		if teamCount == 0 {
+			teamCount = len(config.Matching.Teams)
+		} else if teamCount != len(config.Matching.Teams) {
+			return nil, errors.New("登録チーム数とチーム数が一致しません")
+		}
+	}
 	mo := &MatchOptimizer{
-		outputPath:   config.Matching.OutputPath,
 		InfiniteLoop: config.Matching.InfiniteLoop,
-		TeamCount:    config.Matching.TeamCount,
+		TeamCount:    teamCount,
 		GameCount:    config.Matching.GameCount,
 		RoleNumMap:   roles,
 		IdxTeamMap:   map[int]string{},
 	}
+	mo.configure(config)
+	for idx, team := range config.Matching.Teams {
+		if mo.isRegistered(team) {
+			return nil, errors.New("登録チームが重複しています")
+		}
+		mo.IdxTeamMap[idx] = team
+		slog.Info("登録チームを追加しました", "team", team, "idx", idx)
+	}
 	mo.initialize()
 	return mo, nil
 }
 
+func (mo *MatchOptimizer) configure(config model.Config) {
+	mo.outputPath = config.Matching.OutputPath
+	mo.maxRetries = config.Matching.Retry.MaxCount
+	mo.retryBackoff = config.Matching.Retry.Backoff
+	mo.substitute = config.Matching.Retry.Substitute
+	mo.maxSubstitutions = config.Matching.Retry.MaxSubstitutions
+	if mo.maxSubstitutions <= 0 {
+		mo.maxSubstitutions = defaultMaxSubstitutions
+	}
+}
+
 func (mo *MatchOptimizer) getMatches() []map[model.Role][]string {
 	mo.mu.Lock()
 	defer mo.mu.Unlock()
@@ -141,8 +209,12 @@ func (mo *MatchOptimizer) getMatches() []map[model.Role][]string {
 		slog.Info("スケジュールされたマッチがないため、新たに追加します")
 		mo.append()
 	}
+	now := time.Now()
 	matches := []map[model.Role][]string{}
 	for _, match := range mo.ScheduledMatches {
+		if match.IsWaitingRetry(now) {
+			continue
+		}
 		matches = append(matches, util.IdxMatchToTeamNameMatch(mo.IdxTeamMap, match.RoleIdxs))
 	}
 	sort.Slice(mo.ScheduledMatches, func(i, j int) bool {
@@ -154,11 +226,9 @@ func (mo *MatchOptimizer) getMatches() []map[model.Role][]string {
 func (mo *MatchOptimizer) updateTeam(team string) {
 	mo.mu.Lock()
 	defer mo.mu.Unlock()
-	for _, t := range mo.IdxTeamMap {
-		if t == team {
-			slog.Info("チームが既に登録されています", "team", team)
-			return
-		}
+	if mo.isRegistered(team) {
+		slog.Info("チームが既に登録されています", "team", team)
+		return
 	}
 	idx := len(mo.IdxTeamMap)
 	if idx >= mo.TeamCount {
@@ -170,6 +240,50 @@ func (mo *MatchOptimizer) updateTeam(team string) {
 	mo.save()
 }
 
+func (mo *MatchOptimizer) isRegistered(team string) bool {
+	for _, t := range mo.IdxTeamMap {
+		if t == team {
+			return true
+		}
+	}
+	return false
+}
+
+func (mo *MatchOptimizer) isRegisteredTeam(team string) bool {
+	mo.mu.RLock()
+	defer mo.mu.RUnlock()
+	return mo.isRegistered(team)
+}
+
+func (mo *MatchOptimizer) getTeams() map[int]string {
+	mo.mu.RLock()
+	defer mo.mu.RUnlock()
+	teams := make(map[int]string, len(mo.IdxTeamMap))
+	maps.Copy(teams, mo.IdxTeamMap)
+	return teams
+}
+
+func (mo *MatchOptimizer) replaceTeam(idx int, team string) error {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	if idx < 0 || idx >= mo.TeamCount {
+		return errors.New("チームのインデックスが範囲外です")
+	}
+	if team == "" {
+		return errors.New("チーム名が空です")
+	}
+	for i, t := range mo.IdxTeamMap {
+		if t == team && i != idx {
+			return errors.New("チームが既に別のインデックスで登録されています")
+		}
+	}
+	previous := mo.IdxTeamMap[idx]
+	mo.IdxTeamMap[idx] = team
+	slog.Info("チームを置換しました", "idx", idx, "previous", previous, "team", team)
+	mo.save()
+	return nil
+}
+
 func (mo *MatchOptimizer) initialize() error {
 	mo.mu.Lock()
 	slog.Info("マッチオプティマイザを初期化します")
@@ -250,6 +364,143 @@ func (mo *MatchOptimizer) setMatchWeight(match map[model.Role][]string, weight f
 	slog.Warn("スケジュールされたマッチが見つかりませんでした")
 }
 
+func (mo *MatchOptimizer) setMatchFailed(match map[model.Role][]string, errorTeams []string) {
+	mo.mu.Lock()
+	defer mo.mu.Unlock()
+	idxMatch := util.TeamNameMatchToIdxMatch(mo.IdxTeamMap, match)
+
+	for i, scheduledMatch := range mo.ScheduledMatches {
+		if !scheduledMatch.Equal(model.MatchWeight{RoleIdxs: idxMatch}) {
+			continue
+		}
+		failure := model.MatchFailure{
+			Reason:     model.MF_NO_WINNER,
+			ErrorTeams: errorTeams,
+			Timestamp:  time.Now(),
+		}
+		if len(errorTeams) > 0 {
+			failure.Reason = model.MF_AGENT_ERROR
+		}
+		scheduled := &mo.ScheduledMatches[i]
+		scheduled.Retries++
+
+		if scheduled.Retries > mo.maxRetries {
+			if mo.substitute && len(errorTeams) > 0 && scheduled.SubstitutionCount() < mo.maxSubstitutions {
+				failure.Substitutions = mo.substituteTeams(scheduled, errorTeams)
+			}
+			if len(failure.Substitutions) > 0 {
+				scheduled.Retries = 0
+				scheduled.RetryAfter = nil
+				slog.Info("エラーが発生したチームを代替チームに置換しました", "substitutions", failure.Substitutions)
+			} else {
+				scheduled.Weight = 0
+				scheduled.RetryAfter = nil
+				slog.Warn("再試行回数が上限に達したため、マッチの重みを0に設定しました", "retries", scheduled.Retries)
+			}
+		} else {
+			retryAfter := time.Now().Add(retryDelay(mo.retryBackoff, scheduled.Retries))
+			scheduled.RetryAfter = &retryAfter
+			slog.Info("マッチの再試行を予約しました", "retries", scheduled.Retries, "retry_after", retryAfter)
+		}
+		scheduled.Failures = append(scheduled.Failures, failure)
+		mo.save()
+		return
+	}
+	slog.Warn("スケジュールされたマッチが見つかりませんでした")
+}
+
+func retryDelay(base time.Duration, retries int) time.Duration {
+	delay := base
+	for i := 1; i < retries && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+func (mo *MatchOptimizer) substituteTeams(match *model.MatchWeight, errorTeams []string) []model.MatchSubstitution {
+	used := make(map[int]bool)
+	for _, idxs := range match.RoleIdxs {
+		for _, idx := range idxs {
+			used[idx] = true
+		}
+	}
+	load := make(map[int]int)
+	for _, scheduledMatch := range mo.ScheduledMatches {
+		for _, idxs := range scheduledMatch.RoleIdxs {
+			for _, idx := range idxs {
+				load[idx]++
+			}
+		}
+	}
+
+	substitutions := []model.MatchSubstitution{}
+	for _, team := range errorTeams {
+		from := -1
+		for idx, t := range mo.IdxTeamMap {
+			if t == team && used[idx] {
+				from = idx
+				break
+			}
+		}
+		if from == -1 {
+			continue
+		}
+		candidates := []int{}
+		for idx := range mo.IdxTeamMap {
+			if !used[idx] {
+				candidates = append(candidates, idx)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if load[candidates[i]] != load[candidates[j]] {
+				return load[candidates[i]] < load[candidates[j]]
+			}
+			return candidates[i] < candidates[j]
+		})
+		to := -1
+		var roleIdxs map[model.Role][]int
+		for _, idx := range candidates {
+			roleIdxs = replaceIdx(match.RoleIdxs, from, idx)
+			if !mo.isScheduled(model.MatchWeight{RoleIdxs: roleIdxs}, match) {
+				to = idx
+				break
+			}
+		}
+		if to == -1 {
+			slog.Warn("代替可能なチームがありません", "team", team)
+			break
+		}
+		match.RoleIdxs = roleIdxs
+		used[to] = true
+		substitutions = append(substitutions, model.MatchSubstitution{From: from, To: to})
+	}
+	return substitutions
+}
+
+func replaceIdx(roleIdxs map[model.Role][]int, from int, to int) map[model.Role][]int {
+	replaced := make(map[model.Role][]int, len(roleIdxs))
+	for role, idxs := range roleIdxs {
+		replaced[role] = make([]int, len(idxs))
+		for i, idx := range idxs {
+			if idx == from {
+				idx = to
+			}
+			replaced[role][i] = idx
+		}
+	}
+	return replaced
+}
+
+// 置換後のマッチが他のスケジュール済みのマッチと重複しないかを確認する
+func (mo *MatchOptimizer) isScheduled(match model.MatchWeight, exclude *model.MatchWeight) bool {
+	for i := range mo.ScheduledMatches {
+		if &mo.ScheduledMatches[i] != exclude && mo.ScheduledMatches[i].Equal(match) {
+			return true
+		}
+	}
+	return false
+}
+
 func (mo *MatchOptimizer) save() error {
 	jsonData, err := json.Marshal(mo)
 	if err != nil {
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+func TestMatchOptimizerRetry(t *testing.T) {
+	config, err := model.LoadFromPath("../test/config/optimize.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Matching.OutputPath = filepath.Join(t.TempDir(), "match_optimizer.json")
+	config.Matching.TeamCount = 0
+	config.Matching.Teams = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf"}
+	config.Matching.Retry.MaxCount = 2
+	config.Matching.Retry.Backoff = time.Minute
+	config.Matching.Retry.Substitute = true
+
+	mo, err := NewMatchOptimizerFromConfig(*config)
+	if err != nil {
+		t.Fatalf("マッチオプティマイザの初期化に失敗しました: %v", err)
+	}
+	scheduled := &mo.ScheduledMatches[0]
+	teams := func() map[model.Role][]string {
+		return util.IdxMatchToTeamNameMatch(mo.IdxTeamMap, scheduled.RoleIdxs)
+	}
+	errorTeam := func() string {
+		for _, names := range teams() {
+			return names[0]
+		}
+		return ""
+	}
+
+	now := time.Now()
+	mo.setMatchFailed(teams(), nil)
+	if scheduled.Retries != 1 || scheduled.Failures[0].Reason != model.MF_NO_WINNER {
+		t.Fatalf("1回目の失敗が記録されていません: %+v", scheduled)
+	}
+	if !scheduled.IsWaitingRetry(now) || scheduled.IsWaitingRetry(now.Add(2*time.Minute)) {
+		t.Errorf("1回目の再試行までの待機時間が一致しません: %v", scheduled.RetryAfter)
+	}
+	mo.setMatchFailed(teams(), []string{errorTeam()})
+	if scheduled.Failures[1].Reason != model.MF_AGENT_ERROR {
+		t.Errorf("エラーによる失敗が記録されていません: %+v", scheduled.Failures[1])
+	}
+	if !scheduled.IsWaitingRetry(now.Add(90*time.Second)) || scheduled.IsWaitingRetry(now.Add(3*time.Minute)) {
+		t.Errorf("2回目の再試行までの待機時間が2倍になっていません: %v", scheduled.RetryAfter)
+	}
+
+	substituted := errorTeam()
+	mo.setMatchFailed(teams(), []string{substituted})
+	if len(scheduled.Failures[2].Substitutions) != 1 || scheduled.Retries != 0 || scheduled.RetryAfter != nil || scheduled.Weight == 0 {
+		t.Fatalf("エラーが発生したチームが置換されていません: %+v", scheduled)
+	}
+	for _, names := range teams() {
+		if slices.Contains(names, substituted) {
+			t.Errorf("置換されたチームがマッチに残っています: %s", substituted)
+		}
+	}
+
+	for range 3 {
+		mo.setMatchFailed(teams(), []string{errorTeam()})
+	}
+	if scheduled.Weight != 0 || scheduled.SubstitutionCount() != 1 {
+		t.Errorf("置換回数の上限に達したマッチの重みが0になっていません: %+v", scheduled)
+	}
+
+	config.Matching.Retry.MaxCount = 100
+	config.Matching.Retry.Substitute = false
+	mo, err = NewMatchOptimizerFromConfig(*config)
+	if err != nil {
+		t.Fatalf("マッチオプティマイザの初期化に失敗しました: %v", err)
+	}
+	scheduled = &mo.ScheduledMatches[0]
+	for range 80 {
+		mo.setMatchFailed(teams(), nil)
+	}
+	if !scheduled.IsWaitingRetry(time.Now()) || scheduled.IsWaitingRetry(time.Now().Add(time.Hour+time.Minute)) {
+		t.Errorf("再試行までの待機時間が上限を超えています: %v", scheduled.RetryAfter)
+	}
+}
+
+func TestMatchOptimizerSubstituteDuplicate(t *testing.T) {
+	mo := &MatchOptimizer{
+		outputPath:       filepath.Join(t.TempDir(), "match_optimizer.json"),
+		substitute:       true,
+		maxSubstitutions: 2,
+		IdxTeamMap:       map[int]string{0: "alpha", 1: "bravo", 2: "charlie", 3: "delta"},
+		ScheduledMatches: []model.MatchWeight{
+			{RoleIdxs: map[model.Role][]int{model.R_VILLAGER: {0, 1}}, Weight: 1},
+			{RoleIdxs: map[model.Role][]int{model.R_VILLAGER: {0, 2}}, Weight: 1},
+			{RoleIdxs: map[model.Role][]int{model.R_VILLAGER: {1, 3}}, Weight: 1},
+		},
+	}
+	mo.setMatchFailed(map[model.Role][]string{model.R_VILLAGER: {"alpha", "bravo"}}, []string{"bravo"})
+
+	scheduled := mo.ScheduledMatches[0]
+	if !scheduled.Equal(model.MatchWeight{RoleIdxs: map[model.Role][]int{model.R_VILLAGER: {0, 3}}}) {
+		t.Errorf("置換後のマッチが他のスケジュール済みのマッチと重複しています: %+v", scheduled.RoleIdxs)
+	}
+	if len(scheduled.Failures) != 1 || len(scheduled.Failures[0].Substitutions) != 1 || scheduled.Failures[0].Substitutions[0].To != 3 {
+		t.Errorf("置換が記録されていません: %+v", scheduled.Failures)
+	}
+
+	mo.ScheduledMatches = append(mo.ScheduledMatches, model.MatchWeight{RoleIdxs: map[model.Role][]int{model.R_VILLAGER: {2, 3}}, Weight: 1})
+	mo.setMatchFailed(map[model.Role][]string{model.R_VILLAGER: {"alpha", "delta"}}, []string{"alpha"})
+	if scheduled := mo.ScheduledMatches[0]; scheduled.Weight != 0 || scheduled.SubstitutionCount() != 1 {
+		t.Errorf("重複しない代替チームがない場合にマッチの重みが0になっていません: %+v", scheduled)
+	}
+}
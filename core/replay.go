@@ -0,0 +1,270 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/service"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+type ReplayLog struct {
+	GameID  string `json:"game_id"`
+	WinSide string `json:"win_side"`
+	Agents  []struct {
+		Idx      int    `json:"idx"`
+		Team     string `json:"team"`
+		Name     string `json:"name"`
+		GameName string `json:"game_name"`
+		Role     string `json:"role"`
+	} `json:"agents"`
+	Entries []ReplayEntry `json:"entries"`
+}
+
+type ReplayEntry struct {
+	Agent    string `json:"agent"`
+	Request  string `json:"request"`
+	Response string `json:"response"`
+	Error    string `json:"error"`
+	packet   struct {
+		Request string `json:"request"`
+		Info    *struct {
+			Day           int     `json:"day"`
+			Agent         string  `json:"agent"`
+			ExecutedAgent *string `json:"executed_agent"`
+			AttackedAgent *string `json:"attacked_agent"`
+		} `json:"info"`
+	}
+}
+
+type LogReplayer struct {
+	entries     []ReplayEntry
+	consumed    []bool
+	queues      map[string][]int
+	Divergences []string
+}
+
+func NewLogReplayer(entries []ReplayEntry) *LogReplayer {
+	lr := &LogReplayer{
+		entries:  entries,
+		consumed: make([]bool, len(entries)),
+		queues:   make(map[string][]int),
+	}
+	for i := range lr.entries {
+		if err := json.Unmarshal([]byte(lr.entries[i].Request), &lr.entries[i].packet); err != nil {
+			slog.Warn("記録されたリクエストのパースに失敗しました", "idx", i, "error", err)
+		}
+		lr.queues[lr.entries[i].Agent] = append(lr.queues[lr.entries[i].Agent], i)
+	}
+	return lr
+}
+
+func (lr *LogReplayer) Respond(agent *model.Agent, packet model.Packet) (string, error) {
+	queue := lr.queues[agent.String()]
+	if len(queue) == 0 {
+		lr.diverge("記録されたリクエストがありません: agent=%s, request=%s", agent.String(), packet.Request.Type)
+		agent.HasError = true
+		return "", errors.New("記録されたリクエストがありません")
+	}
+	idx := queue[0]
+	lr.queues[agent.String()] = queue[1:]
+	lr.consumed[idx] = true
+	entry := lr.entries[idx]
+	if entry.packet.Request != packet.Request.Type {
+		lr.diverge("リクエストが一致しません: agent=%s, expected=%s, actual=%s", agent.String(), entry.packet.Request, packet.Request.Type)
+	}
+	if entry.Error != "" {
+		if entry.Error != model.ErrResponseTimeout.Error() {
+			agent.HasError = true
+		}
+		return entry.Response, errors.New(entry.Error)
+	}
+	return entry.Response, nil
+}
+
+func (lr *LogReplayer) Order(request model.Request, agents []*model.Agent) {
+	order := []string{}
+	for i, entry := range lr.entries {
+		if len(order) == len(agents) {
+			break
+		}
+		if lr.consumed[i] || entry.packet.Request != request.Type || slices.Contains(order, entry.Agent) {
+			continue
+		}
+		if slices.ContainsFunc(agents, func(a *model.Agent) bool { return a.String() == entry.Agent }) {
+			order = append(order, entry.Agent)
+		}
+	}
+	slices.SortStableFunc(agents, func(a, b *model.Agent) int {
+		ai, bi := slices.Index(order, a.String()), slices.Index(order, b.String())
+		if ai == -1 {
+			ai = len(order)
+		}
+		if bi == -1 {
+			bi = len(order)
+		}
+		return ai - bi
+	})
+}
+
+func (lr *LogReplayer) Select(request model.Request, day int, candidates []model.Agent) model.Agent {
+	slices.SortFunc(candidates, func(a, b model.Agent) int { return a.Idx - b.Idx })
+	for i, entry := range lr.entries {
+		if lr.consumed[i] || entry.packet.Info == nil || entry.packet.Info.Day <= day {
+			continue
+		}
+		var name *string
+		switch request {
+		case model.R_VOTE:
+			name = entry.packet.Info.ExecutedAgent
+		case model.R_ATTACK:
+			name = entry.packet.Info.AttackedAgent
+		}
+		if name == nil {
+			break
+		}
+		for _, candidate := range candidates {
+			if candidate.String() == *name {
+				return candidate
+			}
+		}
+		break
+	}
+	slog.Warn("記録から対象を特定できなかったため、インデックスが最小の候補を選択します", "request", request.Type, "day", day)
+	return candidates[0]
+}
+
+func (lr *LogReplayer) diverge(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	slog.Warn("リプレイが記録と一致しません", "detail", message)
+	lr.Divergences = append(lr.Divergences, message)
+}
+
+func (lr *LogReplayer) Remaining() int {
+	count := 0
+	for _, consumed := range lr.consumed {
+		if !consumed {
+			count++
+		}
+	}
+	return count
+}
+
+func Replay(config model.Config, jsonPath string, logPath string) error {
+	data, err := readLogFile(jsonPath)
+	if err != nil {
+		slog.Error("JSONログの読み込みに失敗しました", "error", err)
+		return err
+	}
+	var replayLog ReplayLog
+	if err := json.Unmarshal(data, &replayLog); err != nil {
+		slog.Error("JSONログのパースに失敗しました", "error", err)
+		return err
+	}
+	setting, err := model.NewSetting(config)
+	if err != nil {
+		return err
+	}
+
+	replayer := NewLogReplayer(replayLog.Entries)
+	agents := make([]*model.Agent, 0, len(replayLog.Agents))
+	initializeEntries := slices.DeleteFunc(slices.Clone(replayer.entries), func(entry ReplayEntry) bool {
+		return entry.packet.Request != model.R_INITIALIZE.Type
+	})
+	if len(initializeEntries) < len(replayLog.Agents) {
+		return errors.New("INITIALIZEリクエストの記録が不足しています")
+	}
+	for i, a := range replayLog.Agents {
+		gameName := a.GameName
+		if gameName == "" {
+			gameName = initializeEntries[i].Agent
+		}
+		agents = append(agents, &model.Agent{
+			Idx:          a.Idx,
+			TeamName:     a.Team,
+			OriginalName: a.Name,
+			GameName:     gameName,
+			Role:         model.RoleFromString(a.Role),
+		})
+	}
+
+	tempDir, err := os.MkdirTemp("", "replay")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	loggerConfig := config
+	loggerConfig.GameLogger.OutputDir = tempDir
+	loggerConfig.GameLogger.Filename = "{game_id}"
+
+	game := logic.NewReplayGame(&config, setting, replayLog.GameID, agents, replayer)
+	game.AddObserver(service.NewGameLogger(loggerConfig))
+	winSide := game.Start()
+
+	mismatches := slices.Clone(replayer.Divergences)
+	if remaining := replayer.Remaining(); remaining > 0 {
+		mismatches = append(mismatches, fmt.Sprintf("未使用の記録があります: %d", remaining))
+	}
+	if string(winSide) != replayLog.WinSide {
+		mismatches = append(mismatches, fmt.Sprintf("勝利陣営が一致しません: expected=%s, actual=%s", replayLog.WinSide, winSide))
+	}
+	if logPath != "" {
+		expected, err := readLogFile(logPath)
+		if err != nil {
+			slog.Error("ゲームログの読み込みに失敗しました", "error", err)
+			return err
+		}
+		actual, err := os.ReadFile(filepath.Join(tempDir, replayLog.GameID+".log"))
+		if err != nil {
+			slog.Error("リプレイしたゲームログの読み込みに失敗しました", "error", err)
+			return err
+		}
+		mismatches = append(mismatches, compareGameLogs(string(expected), string(actual))...)
+	}
+
+	if len(mismatches) > 0 {
+		for _, mismatch := range mismatches {
+			slog.Error("リプレイ結果が記録と一致しません", "detail", mismatch)
+		}
+		return fmt.Errorf("リプレイ結果が記録と一致しません: %d件", len(mismatches))
+	}
+	slog.Info("リプレイ結果が記録と一致しました", "id", replayLog.GameID, "winSide", winSide)
+	return nil
+}
+
+func readLogFile(path string) ([]byte, error) {
+	reader, err := util.OpenLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func compareGameLogs(expected string, actual string) []string {
+	expectedLines := strings.Split(strings.TrimRight(expected, "\n"), "\n")
+	actualLines := strings.Split(strings.TrimRight(actual, "\n"), "\n")
+	mismatches := []string{}
+	for i := range max(len(expectedLines), len(actualLines)) {
+		var e, a string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if e != a {
+			mismatches = append(mismatches, fmt.Sprintf("ゲームログの%d行目が一致しません: expected=%q, actual=%q", i+1, e, a))
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,482 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+const (
+	RF_CSV  = "csv"
+	RF_JSON = "json"
+	RF_HTML = "html"
+)
+
+type Report struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Teams       []TeamReport         `json:"teams"`
+	Comparisons []PairwiseComparison `json:"comparisons"`
+	Coverage    []CoverageReport     `json:"coverage"`
+	Latency     []LatencyReport      `json:"latency"`
+}
+
+type TeamReport struct {
+	Team            string       `json:"team"`
+	Total           RoleReport   `json:"total"`
+	AdjustedWinRate float64      `json:"adjusted_win_rate"`
+	Sides           []RoleReport `json:"sides"`
+	Roles           []RoleReport `json:"roles"`
+}
+
+type RoleReport struct {
+	Role         string  `json:"role"`
+	Games        int     `json:"games"`
+	Succeed      int     `json:"succeed"`
+	None         int     `json:"none"`
+	Win          int     `json:"win"`
+	Lose         int     `json:"lose"`
+	Error        int     `json:"error"`
+	WinRate      float64 `json:"win_rate"`
+	WinRateLower float64 `json:"win_rate_lower"`
+	WinRateUpper float64 `json:"win_rate_upper"`
+	ErrorRate    float64 `json:"error_rate"`
+}
+
+type PairwiseComparison struct {
+	TeamA          string  `json:"team_a"`
+	TeamB          string  `json:"team_b"`
+	WinRateA       float64 `json:"win_rate_a"`
+	WinRateB       float64 `json:"win_rate_b"`
+	Z              float64 `json:"z"`
+	PValue         float64 `json:"p_value"`
+	AdjustedPValue float64 `json:"adjusted_p_value"`
+	Significant    bool    `json:"significant"`
+}
+
+type CoverageReport struct {
+	Idx       int            `json:"idx"`
+	Team      string         `json:"team"`
+	Scheduled map[string]int `json:"scheduled"`
+	Ended     map[string]int `json:"ended"`
+	Coverage  float64        `json:"coverage"`
+}
+
+func NewReport() *Report {
+	return &Report{
+		GeneratedAt: time.Now(),
+		Teams:       []TeamReport{},
+		Comparisons: []PairwiseComparison{},
+		Coverage:    []CoverageReport{},
+		Latency:     []LatencyReport{},
+	}
+}
+
+func newRoleReport(role string, count Count) RoleReport {
+	report := RoleReport{
+		Role:    role,
+		Games:   count.Succeed + count.None,
+		Succeed: count.Succeed,
+		None:    count.None,
+		Win:     count.Win,
+		Lose:    count.Lose,
+		Error:   count.Error,
+	}
+	if count.Succeed > 0 {
+		report.WinRate = float64(count.Win) / float64(count.Succeed)
+	}
+	report.WinRateLower, report.WinRateUpper = wilsonInterval(count.Win, count.Succeed, confidenceZ)
+	if report.Games > 0 {
+		report.ErrorRate = float64(count.Error) / float64(report.Games)
+	}
+	return report
+}
+
+func (r *Report) setCounts(counts map[string]map[model.Role]*Count) {
+	globalSides := make(map[model.Team]*Count)
+	for _, roles := range counts {
+		for role, count := range roles {
+			side := role.Team
+			if _, exists := globalSides[side]; !exists {
+				globalSides[side] = &Count{}
+			}
+			globalSides[side].add(*count)
+		}
+	}
+
+	r.Teams = make([]TeamReport, 0, len(counts))
+	for team, roles := range counts {
+		total := Count{}
+		sides := make(map[model.Team]*Count)
+		teamReport := TeamReport{Team: team, Sides: []RoleReport{}, Roles: make([]RoleReport, 0, len(roles))}
+		for role, count := range roles {
+			teamReport.Roles = append(teamReport.Roles, newRoleReport(role.Name, *count))
+			total.add(*count)
+			side := role.Team
+			if _, exists := sides[side]; !exists {
+				sides[side] = &Count{}
+			}
+			sides[side].add(*count)
+		}
+		slices.SortFunc(teamReport.Roles, func(a, b RoleReport) int {
+			return strings.Compare(a.Role, b.Role)
+		})
+		teamReport.Total = newRoleReport("", total)
+
+		adjusted := 0.0
+		for _, side := range []model.Team{model.T_VILLAGER, model.T_WEREWOLF} {
+			count, exists := sides[side]
+			if !exists || count.Succeed == 0 {
+				if global, exists := globalSides[side]; exists && global.Succeed > 0 {
+					adjusted += float64(global.Win) / float64(global.Succeed)
+				}
+				continue
+			}
+			sideReport := newRoleReport(string(side), *count)
+			teamReport.Sides = append(teamReport.Sides, sideReport)
+			adjusted += sideReport.WinRate
+		}
+		teamReport.AdjustedWinRate = adjusted / 2
+		r.Teams = append(r.Teams, teamReport)
+	}
+	slices.SortFunc(r.Teams, func(a, b TeamReport) int {
+		return strings.Compare(a.Team, b.Team)
+	})
+
+	r.Comparisons = []PairwiseComparison{}
+	pValues := []float64{}
+	for i := range r.Teams {
+		for j := i + 1; j < len(r.Teams); j++ {
+			successesA, trialsA := sideCounts(r.Teams[i])
+			successesB, trialsB := sideCounts(r.Teams[j])
+			z, p := sideAdjustedTest(successesA, trialsA, successesB, trialsB)
+			r.Comparisons = append(r.Comparisons, PairwiseComparison{
+				TeamA:    r.Teams[i].Team,
+				TeamB:    r.Teams[j].Team,
+				WinRateA: r.Teams[i].AdjustedWinRate,
+				WinRateB: r.Teams[j].AdjustedWinRate,
+				Z:        z,
+				PValue:   p,
+			})
+			pValues = append(pValues, p)
+		}
+	}
+	for i, p := range holmAdjust(pValues) {
+		comparison := &r.Comparisons[i]
+		comparison.AdjustedPValue = p
+		comparison.Significant = p < significanceLevel
+		if comparison.Significant {
+			slog.Info("勝率に有意差があります", "team_a", comparison.TeamA, "team_b", comparison.TeamB, "p_value", comparison.AdjustedPValue)
+		}
+	}
+}
+
+func sideCounts(team TeamReport) ([]int, []int) {
+	successes, trials := make([]int, 2), make([]int, 2)
+	for i, side := range []model.Team{model.T_VILLAGER, model.T_WEREWOLF} {
+		for _, report := range team.Sides {
+			if report.Role == string(side) {
+				successes[i], trials[i] = report.Win, report.Succeed
+			}
+		}
+	}
+	return successes, trials
+}
+
+func (r *Report) addCoverage(idx int, team string, scheduled map[model.Role]int, ended map[model.Role]int) {
+	coverage := CoverageReport{
+		Idx:       idx,
+		Team:      team,
+		Scheduled: make(map[string]int),
+		Ended:     make(map[string]int),
+	}
+	scheduledSum, endedSum := 0, 0
+	for role, count := range scheduled {
+		coverage.Scheduled[role.Name] = count
+		scheduledSum += count
+	}
+	for role, count := range ended {
+		coverage.Ended[role.Name] = count
+		endedSum += count
+	}
+	if scheduledSum+endedSum > 0 {
+		coverage.Coverage = float64(endedSum) / float64(scheduledSum+endedSum)
+	}
+	r.Coverage = append(r.Coverage, coverage)
+	slices.SortFunc(r.Coverage, func(a, b CoverageReport) int {
+		return a.Idx - b.Idx
+	})
+}
+
+func (r *Report) Export(format string, path string) error {
+	if path == "" {
+		path = "report." + format
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch format {
+	case RF_CSV:
+		err = r.exportCSV(path)
+	case RF_JSON:
+		err = r.exportJSON(path)
+	case RF_HTML:
+		err = r.exportHTML(path)
+	default:
+		return errors.New("不明なレポート形式です: " + format)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("レポートを出力しました", "format", format, "path", path)
+	return nil
+}
+
+func (r *Report) exportJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (r *Report) exportCSV(path string) error {
+	records := [][]string{{"team", "role", "games", "succeed", "none", "win", "lose", "error", "win_rate", "win_rate_lower", "win_rate_upper", "error_rate", "adjusted_win_rate"}}
+	for _, team := range r.Teams {
+		rows := slices.Clone(team.Roles)
+		for _, side := range team.Sides {
+			side.Role = "SIDE_" + side.Role
+			rows = append(rows, side)
+		}
+		rows = append(rows, team.Total)
+		for _, role := range rows {
+			name := role.Role
+			adjusted := ""
+			if name == "" {
+				name = "ALL"
+				adjusted = formatRate(team.AdjustedWinRate)
+			}
+			records = append(records, []string{
+				team.Team,
+				name,
+				strconv.Itoa(role.Games),
+				strconv.Itoa(role.Succeed),
+				strconv.Itoa(role.None),
+				strconv.Itoa(role.Win),
+				strconv.Itoa(role.Lose),
+				strconv.Itoa(role.Error),
+				formatRate(role.WinRate),
+				formatRate(role.WinRateLower),
+				formatRate(role.WinRateUpper),
+				formatRate(role.ErrorRate),
+				adjusted,
+			})
+		}
+	}
+	if err := writeCSV(path, records); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(path)
+	records = [][]string{{"team_a", "team_b", "win_rate_a", "win_rate_b", "z", "p_value", "adjusted_p_value", "significant"}}
+	for _, comparison := range r.Comparisons {
+		records = append(records, []string{
+			comparison.TeamA,
+			comparison.TeamB,
+			formatRate(comparison.WinRateA),
+			formatRate(comparison.WinRateB),
+			formatRate(comparison.Z),
+			formatRate(comparison.PValue),
+			formatRate(comparison.AdjustedPValue),
+			strconv.FormatBool(comparison.Significant),
+		})
+	}
+	if err := writeCSV(strings.TrimSuffix(path, ext)+"_comparisons"+ext, records); err != nil {
+		return err
+	}
+
+	if len(r.Latency) > 0 {
+		records = [][]string{{"team", "request", "count", "mean_ms", "p50_ms", "p90_ms", "p99_ms", "max_ms", "timeouts", "name_fallbacks"}}
+		slowRecords := [][]string{{"team", "game_id", "agent", "request", "day", "duration_ms", "error"}}
+		for _, latency := range r.Latency {
+			for _, request := range latency.Requests {
+				records = append(records, []string{
+					latency.Team,
+					request.Request,
+					strconv.Itoa(request.Count),
+					strconv.FormatFloat(request.Mean, 'f', 1, 64),
+					strconv.FormatInt(request.P50, 10),
+					strconv.FormatInt(request.P90, 10),
+					strconv.FormatInt(request.P99, 10),
+					strconv.FormatInt(request.Max, 10),
+					strconv.Itoa(request.Timeouts),
+					strconv.Itoa(request.NameFallbacks),
+				})
+			}
+			for _, slow := range latency.Slowest {
+				slowRecords = append(slowRecords, []string{
+					latency.Team,
+					slow.GameID,
+					slow.Agent,
+					slow.Request,
+					strconv.Itoa(slow.Day),
+					strconv.FormatInt(slow.Duration, 10),
+					slow.Error,
+				})
+			}
+		}
+		if err := writeCSV(strings.TrimSuffix(path, ext)+"_latency"+ext, records); err != nil {
+			return err
+		}
+		if err := writeCSV(strings.TrimSuffix(path, ext)+"_slowest"+ext, slowRecords); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Coverage) == 0 {
+		return nil
+	}
+	roles := r.coverageRoles()
+	header := []string{"idx", "team"}
+	for _, role := range roles {
+		header = append(header, "scheduled_"+strings.ToLower(role), "ended_"+strings.ToLower(role))
+	}
+	records = [][]string{append(header, "coverage")}
+	for _, coverage := range r.Coverage {
+		record := []string{strconv.Itoa(coverage.Idx), coverage.Team}
+		for _, role := range roles {
+			record = append(record, strconv.Itoa(coverage.Scheduled[role]), strconv.Itoa(coverage.Ended[role]))
+		}
+		records = append(records, append(record, formatRate(coverage.Coverage)))
+	}
+	return writeCSV(strings.TrimSuffix(path, ext)+"_coverage"+ext, records)
+}
+
+func (r *Report) coverageRoles() []string {
+	roles := []string{}
+	for _, coverage := range r.Coverage {
+		for role := range coverage.Scheduled {
+			if !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+		for role := range coverage.Ended {
+			if !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+func formatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func writeCSV(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func (r *Report) exportHTML(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return reportTemplate.Execute(file, map[string]any{
+		"Report": r,
+		"Roles":  r.coverageRoles(),
+	})
+}
+
+var reportTemplate = newReportTemplate("report", `<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>aiwolf-nlp-server report</title>
+{{template "style"}}
+</head>
+<body>
+<h1>Standings</h1>
+<p>Generated at {{.Report.GeneratedAt.Format "2006-01-02 15:04:05"}}</p>
+<table>
+<tr><th>Team</th><th>Role</th><th>Games</th><th>Win</th><th>Lose</th><th>None</th><th>Error</th><th>Win rate</th><th>95% CI</th><th>Error rate</th><th>Adjusted win rate</th></tr>
+{{- range .Report.Teams}}
+{{- $team := .Team}}
+{{- $adjusted := .AdjustedWinRate}}
+{{- range .Roles}}
+<tr><td class="name">{{$team}}</td><td class="name">{{.Role}}</td><td>{{.Games}}</td><td>{{.Win}}</td><td>{{.Lose}}</td><td>{{.None}}</td><td>{{.Error}}</td><td>{{percent .WinRate}}</td><td>{{percent .WinRateLower}} - {{percent .WinRateUpper}}</td><td>{{percent .ErrorRate}}</td><td></td></tr>
+{{- end}}
+{{- range .Sides}}
+<tr class="side"><td class="name">{{$team}}</td><td class="name">{{.Role}} side</td><td>{{.Games}}</td><td>{{.Win}}</td><td>{{.Lose}}</td><td>{{.None}}</td><td>{{.Error}}</td><td>{{percent .WinRate}}</td><td>{{percent .WinRateLower}} - {{percent .WinRateUpper}}</td><td>{{percent .ErrorRate}}</td><td></td></tr>
+{{- end}}
+{{- with .Total}}
+<tr class="total"><td class="name">{{$team}}</td><td class="name">ALL</td><td>{{.Games}}</td><td>{{.Win}}</td><td>{{.Lose}}</td><td>{{.None}}</td><td>{{.Error}}</td><td>{{percent .WinRate}}</td><td>{{percent .WinRateLower}} - {{percent .WinRateUpper}}</td><td>{{percent .ErrorRate}}</td><td>{{percent $adjusted}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- if .Report.Comparisons}}
+<h1>Pairwise comparisons</h1>
+<table>
+<tr><th>Team A</th><th>Team B</th><th>Win rate A</th><th>Win rate B</th><th>z</th><th>p-value</th><th>Adjusted p-value</th><th>Significant</th></tr>
+{{- range .Report.Comparisons}}
+<tr{{if .Significant}} class="significant"{{end}}><td class="name">{{.TeamA}}</td><td class="name">{{.TeamB}}</td><td>{{percent .WinRateA}}</td><td>{{percent .WinRateB}}</td><td>{{printf "%.3f" .Z}}</td><td>{{printf "%.4f" .PValue}}</td><td>{{printf "%.4f" .AdjustedPValue}}</td><td>{{if .Significant}}yes{{else}}no{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Report.Latency}}
+<h1>Response latency</h1>
+<table>
+<tr><th>Team</th><th>Request</th><th>Count</th><th>Mean (ms)</th><th>p50 (ms)</th><th>p90 (ms)</th><th>p99 (ms)</th><th>Max (ms)</th><th>Timeouts</th><th>NAME fallbacks</th></tr>
+{{- range .Report.Latency}}
+{{- $team := .Team}}
+{{- range .Requests}}
+<tr><td class="name">{{$team}}</td><td class="name">{{.Request}}</td><td>{{.Count}}</td><td>{{printf "%.1f" .Mean}}</td><td>{{.P50}}</td><td>{{.P90}}</td><td>{{.P99}}</td><td>{{.Max}}</td><td>{{.Timeouts}}</td><td>{{.NameFallbacks}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+<h1>Slowest requests</h1>
+<table>
+<tr><th>Team</th><th>Game</th><th>Agent</th><th>Request</th><th>Day</th><th>Duration (ms)</th><th>Error</th></tr>
+{{- range .Report.Latency}}
+{{- $team := .Team}}
+{{- range .Slowest}}
+<tr><td class="name">{{$team}}</td><td class="name">{{.GameID}}</td><td class="name">{{.Agent}}</td><td class="name">{{.Request}}</td><td>{{.Day}}</td><td>{{.Duration}}</td><td class="name">{{.Error}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- end}}
+{{- if .Report.Coverage}}
+<h1>Schedule coverage</h1>
+<table>
+<tr><th>Idx</th><th>Team</th>{{range $.Roles}}<th>{{.}}</th>{{end}}<th>Coverage</th></tr>
+{{- range .Report.Coverage}}
+{{- $coverage := .}}
+<tr><td>{{.Idx}}</td><td class="name">{{.Team}}</td>{{range $.Roles}}<td>{{lookup $coverage.Ended .}} / {{add (lookup $coverage.Ended .) (lookup $coverage.Scheduled .)}}</td>{{end}}<td>{{percent .Coverage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`)
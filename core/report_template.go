@@ -0,0 +1,35 @@
+package core
+
+import (
+	"html/template"
+	"strconv"
+)
+
+var reportFuncs = template.FuncMap{
+	"percent": func(v float64) string {
+		return strconv.FormatFloat(v*100, 'f', 1, 64) + "%"
+	},
+	"lookup": func(m map[string]int, key string) int {
+		return m[key]
+	},
+	"add": func(a, b int) int {
+		return a + b
+	},
+}
+
+const reportStyle = `<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: right; }
+th { background: #f0f0f0; }
+td.name { text-align: left; }
+tr.side td { color: #555; }
+tr.total td { font-weight: bold; background: #fafafa; }
+tr.significant td { background: #fff4d6; }
+</style>`
+
+func newReportTemplate(name string, text string) *template.Template {
+	tmpl := template.Must(template.New(name).Funcs(reportFuncs).Parse(text))
+	template.Must(tmpl.New("style").Parse(reportStyle))
+	return tmpl
+}
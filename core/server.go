@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,11 +22,15 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+const waitingRoomCheckInterval = 5 * time.Second
+
 type Server struct {
 	config              model.Config
 	upgrader            websocket.Upgrader
 	waitingRoom         *WaitingRoom
 	matchOptimizer      *MatchOptimizer
+	gameQueue           *GameQueue
+	teamQuota           *TeamQuota
 	gameSetting         *model.Setting
 	games               sync.Map
 	mu                  sync.RWMutex
@@ -34,6 +39,7 @@ type Server struct {
 	gameLogger          *service.GameLogger
 	realtimeBroadcaster *service.RealtimeBroadcaster
 	ttsBroadcaster      *service.TTSBroadcaster
+	logArchiver         *service.LogArchiver
 }
 
 func NewServer(config model.Config) (*Server, error) {
@@ -45,10 +51,17 @@ func NewServer(config model.Config) (*Server, error) {
 			},
 		},
 		waitingRoom: NewWaitingRoom(config),
+		gameQueue:   NewGameQueue(config),
+		teamQuota:   NewTeamQuota(config),
 		games:       sync.Map{},
 		mu:          sync.RWMutex{},
 		signaled:    false,
 	}
+	server.gameQueue.OnRelease(func() {
+		if !server.signaled {
+			server.startWaitingGame(false)
+		}
+	})
 	gameSettings, err := model.NewSetting(config)
 	if err != nil {
 		return nil, errors.New("ゲーム設定の作成に失敗しました")
@@ -66,6 +79,9 @@ func NewServer(config model.Config) (*Server, error) {
 	if config.RealtimeBroadcaster.Enable {
 		server.realtimeBroadcaster = service.NewRealtimeBroadcaster(config)
 	}
+	if config.LogArchive.Enable {
+		server.logArchiver = service.NewLogArchiver(config)
+	}
 	if config.Matching.IsOptimize {
 		matchOptimizer, err := NewMatchOptimizer(config)
 		if err != nil {
@@ -103,13 +119,60 @@ func (s *Server) Run() {
 			realtimeGroup.Use(s.verifyMiddleware())
 		}
 		realtimeGroup.Static("/", s.config.RealtimeBroadcaster.OutputDir)
+
+		spectatorGroup := router.Group("/spectate")
+		if s.config.Server.Authentication.Enable {
+			spectatorGroup.Use(s.verifyMiddleware())
+		}
+		s.registerSpectatorRoutes(spectatorGroup, service.RV_AUDIENCE)
 	}
 
-	if s.config.TTSBroadcaster.Enable {
+	if s.config.Server.Authentication.Enable {
+		adminGroup := router.Group("/admin")
+		adminGroup.Use(s.verifyAdminMiddleware())
+		s.registerAdminRoutes(adminGroup)
+		if s.config.RealtimeBroadcaster.Enable {
+			s.registerSpectatorRoutes(adminGroup.Group("/spectate"), service.RV_COMMENTATOR)
+		}
+	} else {
+		slog.Warn("認証が無効のため、管理者向けのエンドポイントを無効にします")
+	}
+
+	if s.config.TTSBroadcaster.Enable && s.ttsBroadcaster != nil {
 		router.Static("/tts", s.config.TTSBroadcaster.SegmentDir)
 		go s.ttsBroadcaster.Start()
 	}
 
+	if s.config.LogArchive.Enable {
+		go s.logArchiver.Start()
+	}
+
+	go func() {
+		ticker := time.NewTicker(waitingRoomCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if s.signaled {
+				return
+			}
+			for _, conn := range s.waitingRoom.RemoveClosedConnections() {
+				s.teamQuota.RemoveConnection(conn.TeamName)
+			}
+		}
+	}()
+
+	if !s.config.Matching.IsOptimize && s.waitingRoom.policy == model.MP_SELF_FALLBACK {
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				if s.signaled {
+					return
+				}
+				s.startWaitingGame(false)
+			}
+		}()
+	}
+
 	go func() {
 		trap := make(chan os.Signal, 1)
 		signal.Notify(trap, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT)
@@ -182,8 +245,28 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	if !s.isRegisteredTeam(conn.TeamName) {
+		slog.Warn("登録されていないチームです", "team_name", conn.TeamName)
+		conn.CloseWithReason(websocket.ClosePolicyViolation, "unregistered team: "+conn.TeamName)
+		return
+	}
+	if err := s.teamQuota.Check(conn.TeamName); err != nil {
+		slog.Warn("チームの制限を超えているため、接続を拒否します", "team_name", conn.TeamName, "error", err)
+		conn.CloseWithReason(websocket.ClosePolicyViolation, err.Error())
+		return
+	}
+	s.teamQuota.AddConnection(conn.TeamName)
 	s.waitingRoom.AddConnection(conn.TeamName, *conn)
+	s.startWaitingGame(true)
+}
 
+func (s *Server) startWaitingGame(verbose bool) {
+	if s.gameQueue.IsFull() {
+		if verbose {
+			slog.Warn("ゲームキューが上限に達しているため、接続を待機させます")
+		}
+		return
+	}
 	var game *logic.Game
 	if s.config.Matching.IsOptimize {
 		s.waitingRoom.connections.Range(func(key, value any) bool {
@@ -194,42 +277,83 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 		matches := s.matchOptimizer.getMatches()
 		roleMapConns, err := s.waitingRoom.GetConnectionsWithMatchOptimizer(matches)
 		if err != nil {
-			slog.Error("待機部屋からの接続の取得に失敗しました", "error", err)
+			if verbose {
+				slog.Error("待機部屋からの接続の取得に失敗しました", "error", err)
+			}
 			return
 		}
 		game = logic.NewGameWithRole(&s.config, s.gameSetting, roleMapConns)
 	} else {
 		connections, err := s.waitingRoom.GetConnections()
 		if err != nil {
-			slog.Error("待機部屋からの接続の取得に失敗しました", "error", err)
+			if verbose {
+				slog.Error("待機部屋からの接続の取得に失敗しました", "error", err)
+			}
 			return
 		}
 		game = logic.NewGame(&s.config, s.gameSetting, connections)
 	}
-	if s.jsonLogger != nil {
-		game.SetJSONLogger(s.jsonLogger)
-	}
-	if s.gameLogger != nil {
-		game.SetGameLogger(s.gameLogger)
-	}
-	if s.realtimeBroadcaster != nil {
-		game.SetRealtimeBroadcaster(s.realtimeBroadcaster)
-	}
-	if s.ttsBroadcaster != nil {
-		game.SetTTSBroadcaster(s.ttsBroadcaster)
+	for _, observer := range s.observers() {
+		game.AddObserver(observer)
 	}
 	s.games.Store(game.GetID(), game)
 
-	go func() {
+	err := s.gameQueue.Enqueue(game, func() {
 		winSide := game.Start()
+		for _, teams := range game.GetRoleTeamNamesMap() {
+			for _, team := range teams {
+				s.teamQuota.RemoveConnection(team)
+			}
+		}
 		if s.config.Matching.IsOptimize {
 			if winSide != model.T_NONE {
 				s.matchOptimizer.setMatchEnd(game.GetRoleTeamNamesMap())
 			} else {
-				s.matchOptimizer.setMatchWeight(game.GetRoleTeamNamesMap(), 0)
+				s.matchOptimizer.setMatchFailed(game.GetRoleTeamNamesMap(), game.GetErrorTeamNames())
 			}
 		}
-	}()
+	})
+	if err != nil {
+		slog.Error("ゲームをキューに追加できないため、接続を切断します", "error", err, "id", game.GetID())
+		s.games.Delete(game.GetID())
+		game.Close()
+		for _, teams := range game.GetRoleTeamNamesMap() {
+			for _, team := range teams {
+				s.teamQuota.RemoveConnection(team)
+			}
+		}
+		return
+	}
+	for _, team := range game.GetTeamNames() {
+		s.teamQuota.RecordGame(team)
+	}
+}
+
+func (s *Server) observers() []model.GameObserver {
+	observers := []model.GameObserver{}
+	if s.jsonLogger != nil {
+		observers = append(observers, s.jsonLogger)
+	}
+	if s.gameLogger != nil {
+		observers = append(observers, s.gameLogger)
+	}
+	if s.realtimeBroadcaster != nil {
+		observers = append(observers, s.realtimeBroadcaster)
+	}
+	if s.ttsBroadcaster != nil {
+		observers = append(observers, s.ttsBroadcaster)
+	}
+	return observers
+}
+
+func (s *Server) isRegisteredTeam(team string) bool {
+	if len(s.config.Matching.Teams) == 0 {
+		return true
+	}
+	if s.matchOptimizer != nil {
+		return s.matchOptimizer.isRegisteredTeam(team)
+	}
+	return slices.Contains(s.config.Matching.Teams, team)
 }
 
 func (s *Server) verifyMiddleware() gin.HandlerFunc {
@@ -249,3 +373,21 @@ func (s *Server) verifyMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (s *Server) verifyAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			token = strings.ReplaceAll(c.GetHeader("Authorization"), "Bearer ", "")
+		}
+		if token == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !util.IsValidAdmin(os.Getenv("SECRET_KEY"), token) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
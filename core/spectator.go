@@ -0,0 +1,184 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/locale"
+	"github.com/aiwolfdial/aiwolf-nlp-server/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultGamesLimit = 50
+	maxGamesLimit     = 500
+)
+
+func (s *Server) registerSpectatorRoutes(group *gin.RouterGroup, view string) {
+	group.GET("/games", s.handleGetGames)
+	group.GET("/:id/ws", func(c *gin.Context) {
+		s.handleSpectatorWebSocket(c, view)
+	})
+	group.GET("/:id/events", func(c *gin.Context) {
+		s.handleSpectatorEvents(c, view)
+	})
+	group.GET("/:id/state", func(c *gin.Context) {
+		s.handleSpectatorState(c, view)
+	})
+}
+
+func (s *Server) handleGetGames(c *gin.Context) {
+	if s.realtimeBroadcaster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": locale.T("リアルタイムブロードキャスターが有効ではありません")})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": locale.T("ページ番号が不正です")})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultGamesLimit)))
+	if err != nil || limit < 1 || limit > maxGamesLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": locale.T("取得件数が不正です")})
+		return
+	}
+	games, total := s.realtimeBroadcaster.Games(page, limit)
+	c.JSON(http.StatusOK, gin.H{"games": games, "total": total, "page": page, "limit": limit})
+}
+
+func (s *Server) subscribe(c *gin.Context, view string, from string) (*service.RealtimeSubscription, bool) {
+	if s.realtimeBroadcaster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": locale.T("リアルタイムブロードキャスターが有効ではありません")})
+		return nil, false
+	}
+	idx := 0
+	if from != "" {
+		var err error
+		idx, err = strconv.Atoi(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": locale.T("再開位置のインデックスが不正です")})
+			return nil, false
+		}
+	}
+	subscription, err := s.realtimeBroadcaster.Subscribe(c.Param("id"), view, idx)
+	if errors.Is(err, service.ErrRealtimeGameNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": locale.T(err.Error())})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": locale.T(err.Error())})
+		return nil, false
+	}
+	return subscription, true
+}
+
+func (s *Server) handleSpectatorState(c *gin.Context, view string) {
+	if s.realtimeBroadcaster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": locale.T("リアルタイムブロードキャスターが有効ではありません")})
+		return
+	}
+	idx := 0
+	if value := c.Query("idx"); value != "" {
+		var err error
+		idx, err = strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": locale.T("パケットのインデックスが不正です")})
+			return
+		}
+	}
+	packet, err := s.realtimeBroadcaster.Reconstruct(c.Param("id"), view, idx)
+	if errors.Is(err, service.ErrRealtimeGameNotFound) || errors.Is(err, service.ErrRealtimePacketNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": locale.T(err.Error())})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": locale.T(err.Error())})
+		return
+	}
+	c.JSON(http.StatusOK, packet)
+}
+
+func (s *Server) handleSpectatorWebSocket(c *gin.Context, view string) {
+	subscription, ok := s.subscribe(c, view, c.Query("from"))
+	if !ok {
+		return
+	}
+	defer subscription.Close()
+
+	conn, err := s.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		slog.Warn("観戦者の接続のアップグレードに失敗しました", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				subscription.Close()
+				return
+			}
+		}
+	}()
+
+	for _, packet := range subscription.Backlog {
+		if err := conn.WriteMessage(websocket.TextMessage, packet.Data); err != nil {
+			return
+		}
+	}
+	for packet := range subscription.Packets {
+		if err := conn.WriteMessage(websocket.TextMessage, packet.Data); err != nil {
+			return
+		}
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+func (s *Server) handleSpectatorEvents(c *gin.Context, view string) {
+	from := c.GetHeader("Last-Event-ID")
+	if from == "" {
+		from = c.Query("from")
+	}
+	subscription, ok := s.subscribe(c, view, from)
+	if !ok {
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	write := func(packet service.RealtimePacket) bool {
+		if _, err := fmt.Fprintf(c.Writer, "id: %d\ndata: %s\n\n", packet.Idx, packet.Data); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+	for _, packet := range subscription.Backlog {
+		if !write(packet) {
+			return
+		}
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case packet, ok := <-subscription.Packets:
+			if !ok {
+				fmt.Fprint(c.Writer, "event: end\ndata: \n\n")
+				c.Writer.Flush()
+				return
+			}
+			if !write(packet) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	confidenceZ       = 1.959963984540054
+	significanceLevel = 0.05
+)
+
+func wilsonInterval(successes int, trials int, z float64) (float64, float64) {
+	if trials == 0 {
+		return 0, 0
+	}
+	n := float64(trials)
+	p := float64(successes) / n
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// 陣営ごとの勝率の差を平均し、陣営の偏りを除いた勝率を比較する
+func sideAdjustedTest(successesA []int, trialsA []int, successesB []int, trialsB []int) (float64, float64) {
+	diff, variance, sides := 0.0, 0.0, 0
+	for i := range trialsA {
+		if trialsA[i] == 0 || trialsB[i] == 0 {
+			continue
+		}
+		nA, nB := float64(trialsA[i]), float64(trialsB[i])
+		pooled := float64(successesA[i]+successesB[i]) / (nA + nB)
+		diff += float64(successesA[i])/nA - float64(successesB[i])/nB
+		variance += pooled * (1 - pooled) * (1/nA + 1/nB)
+		sides++
+	}
+	if sides == 0 || variance == 0 {
+		return 0, 1
+	}
+	z := (diff / float64(sides)) / (math.Sqrt(variance) / float64(sides))
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// Holm法で多重比較を補正したp値を返す
+func holmAdjust(pValues []float64) []float64 {
+	order := make([]int, len(pValues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return pValues[order[i]] < pValues[order[j]]
+	})
+	adjusted := make([]float64, len(pValues))
+	current := 0.0
+	for rank, i := range order {
+		current = math.Max(current, math.Min(1, float64(len(pValues)-rank)*pValues[i]))
+		adjusted[i] = current
+	}
+	return adjusted
+}
@@ -0,0 +1,416 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+var claimRoles = []model.Role{model.R_SEER, model.R_MEDIUM, model.R_BODYGUARD, model.R_VILLAGER, model.R_WEREWOLF, model.R_POSSESSED}
+
+var claimKeywords = map[model.Role]string{
+	model.R_SEER:      `占い師|seer`,
+	model.R_MEDIUM:    `霊媒師|霊能者|medium`,
+	model.R_BODYGUARD: `騎士|狩人|bodyguard`,
+	model.R_VILLAGER:  `村人|villager`,
+	model.R_WEREWOLF:  `人狼|werewolf`,
+	model.R_POSSESSED: `狂人|possessed`,
+}
+
+var claimPatterns = func() map[model.Role]*regexp.Regexp {
+	patterns := make(map[model.Role]*regexp.Regexp)
+	for role, keyword := range claimKeywords {
+		patterns[role] = regexp.MustCompile(`(?i)(?:私|僕|俺|わたし|ぼく|おれ|自分)\S{0,2}[はが]\S{0,3}?(?:` + keyword + `)|(?:` + keyword + `)\s*(?:CO|ＣＯ|カミングアウト)|\bI(?:'m| am) (?:the |a )?(?:` + keyword + `)\b|COMINGOUT\s+\S+\s+` + role.Name)
+	}
+	return patterns
+}()
+
+type TalkReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Teams       []TeamTalkReport `json:"teams"`
+}
+
+type TeamTalkReport struct {
+	Team  string      `json:"team"`
+	Total TalkStats   `json:"total"`
+	Roles []TalkStats `json:"roles"`
+}
+
+type TalkStats struct {
+	Role        string         `json:"role"`
+	Talks       int            `json:"talks"`
+	Whispers    int            `json:"whispers"`
+	Skips       int            `json:"skips"`
+	Overs       int            `json:"overs"`
+	SkipRate    float64        `json:"skip_rate"`
+	OverRate    float64        `json:"over_rate"`
+	LengthMean  float64        `json:"length_mean"`
+	LengthP50   int64          `json:"length_p50"`
+	LengthP90   int64          `json:"length_p90"`
+	LengthMax   int64          `json:"length_max"`
+	Mentions    int            `json:"mentions"`
+	MentionRate float64        `json:"mention_rate"`
+	Vocabulary  int            `json:"vocabulary"`
+	Claims      map[string]int `json:"claims"`
+}
+
+type talkSample struct {
+	talks      int
+	whispers   int
+	skips      int
+	overs      int
+	lengths    []int64
+	mentions   int
+	vocabulary map[string]struct{}
+	claims     map[string]int
+}
+
+func newTalkSample() *talkSample {
+	return &talkSample{
+		vocabulary: make(map[string]struct{}),
+		claims:     make(map[string]int),
+	}
+}
+
+func (s *talkSample) add(event gamelog.TalkEvent, gameNames []string) {
+	if event.Whisper {
+		s.whispers++
+	} else {
+		s.talks++
+	}
+	switch event.Text {
+	case model.T_SKIP, model.T_FORCE_SKIP:
+		s.skips++
+		return
+	case model.T_OVER:
+		s.overs++
+		return
+	}
+	s.lengths = append(s.lengths, int64(len([]rune(event.Text))))
+	s.mentions += countMentions(event.Text, gameNames)
+	for _, token := range tokenize(event.Text) {
+		s.vocabulary[token] = struct{}{}
+	}
+	for role, pattern := range claimPatterns {
+		if pattern.MatchString(event.Text) {
+			s.claims[role.Name]++
+		}
+	}
+}
+
+func (s *talkSample) merge(other *talkSample) {
+	s.talks += other.talks
+	s.whispers += other.whispers
+	s.skips += other.skips
+	s.overs += other.overs
+	s.lengths = append(s.lengths, other.lengths...)
+	s.mentions += other.mentions
+	for token := range other.vocabulary {
+		s.vocabulary[token] = struct{}{}
+	}
+	for role, count := range other.claims {
+		s.claims[role] += count
+	}
+}
+
+func (s *talkSample) stats(role string) TalkStats {
+	stats := TalkStats{
+		Role:       role,
+		Talks:      s.talks,
+		Whispers:   s.whispers,
+		Skips:      s.skips,
+		Overs:      s.overs,
+		Mentions:   s.mentions,
+		Vocabulary: len(s.vocabulary),
+		Claims:     make(map[string]int),
+	}
+	if total := s.talks + s.whispers; total > 0 {
+		stats.SkipRate = float64(s.skips) / float64(total)
+		stats.OverRate = float64(s.overs) / float64(total)
+	}
+	if len(s.lengths) > 0 {
+		lengths := slices.Clone(s.lengths)
+		slices.Sort(lengths)
+		var sum int64
+		for _, length := range lengths {
+			sum += length
+		}
+		stats.LengthMean = float64(sum) / float64(len(lengths))
+		stats.LengthP50 = percentile(lengths, 0.5)
+		stats.LengthP90 = percentile(lengths, 0.9)
+		stats.LengthMax = lengths[len(lengths)-1]
+		stats.MentionRate = float64(s.mentions) / float64(len(lengths))
+	}
+	for _, role := range claimRoles {
+		stats.Claims[role.Name] = s.claims[role.Name]
+	}
+	return stats
+}
+
+// 名前が前方一致する別のエージェントを数えないよう、最も長く一致する名前のみを数える
+func countMentions(text string, names []string) int {
+	count := 0
+	for i := strings.Index(text, "@"); i >= 0; i = strings.Index(text, "@") {
+		text = text[i+1:]
+		matched := ""
+		for _, name := range names {
+			if len(name) > len(matched) && strings.HasPrefix(text, name) && !continuesName(text[len(name):]) {
+				matched = name
+			}
+		}
+		if matched != "" {
+			count++
+			text = text[len(matched):]
+		}
+	}
+	return count
+}
+
+func continuesName(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	r := rest[0]
+	return r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func tokenize(text string) []string {
+	tokens := []string{}
+	var current []rune
+	var currentScript int
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	for _, r := range text {
+		script := runeScript(r)
+		if script == 0 {
+			flush()
+			continue
+		}
+		if script != currentScript {
+			flush()
+		}
+		currentScript = script
+		current = append(current, r)
+	}
+	flush()
+	return tokens
+}
+
+func runeScript(r rune) int {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return 1
+	case unicode.Is(unicode.Hiragana, r):
+		return 2
+	case unicode.Is(unicode.Katakana, r), r == 'ー':
+		return 3
+	case unicode.IsLetter(r), unicode.IsDigit(r):
+		return 4
+	}
+	return 0
+}
+
+func TalkAnalyzer(config model.Config) *TalkReport {
+	report := &TalkReport{
+		GeneratedAt: time.Now(),
+		Teams:       []TeamTalkReport{},
+	}
+	if !config.GameLogger.Enable {
+		slog.Warn("ゲームログが無効になっているため、発話の統計データを分析できません")
+		return report
+	}
+	slog.Info("発話の統計データを分析します")
+
+	samples := make(map[string]map[model.Role]*talkSample)
+	err := util.WalkLogFiles(config.GameLogger.OutputDir, config.LogArchive.OutputDir, util.ArchivePrefixGame, ".log", func(filePath string, file io.Reader) error {
+		reader, err := gamelog.NewReader(file)
+		if err != nil {
+			slog.Warn("ゲームログの読み込みに失敗しました", "file", filePath, "error", err)
+			return nil
+		}
+		agents := make(map[int]gamelog.StatusEvent)
+		gameNames := []string{}
+		for {
+			event, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				slog.Warn("ゲームログのパースに失敗しました", "file", filePath, "error", err)
+				break
+			}
+			switch e := event.(type) {
+			case gamelog.StatusEvent:
+				if e.Day == 0 {
+					agents[e.Idx] = e
+					gameNames = append(gameNames, e.GameName)
+				}
+			case gamelog.TalkEvent:
+				agent, exists := agents[e.AgentIdx]
+				if !exists {
+					continue
+				}
+				team := strings.TrimRight(agent.OriginalName, "1234567890")
+				if _, exists := samples[team]; !exists {
+					samples[team] = make(map[model.Role]*talkSample)
+				}
+				if _, exists := samples[team][agent.Role]; !exists {
+					samples[team][agent.Role] = newTalkSample()
+				}
+				samples[team][agent.Role].add(e, gameNames)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("ファイルの取得に失敗しました", "error", err)
+	}
+
+	for team, roles := range samples {
+		total := newTalkSample()
+		teamReport := TeamTalkReport{Team: team, Roles: make([]TalkStats, 0, len(roles))}
+		for role, sample := range roles {
+			teamReport.Roles = append(teamReport.Roles, sample.stats(role.Name))
+			total.merge(sample)
+		}
+		slices.SortFunc(teamReport.Roles, func(a, b TalkStats) int {
+			return strings.Compare(a.Role, b.Role)
+		})
+		teamReport.Total = total.stats("")
+		slog.Info("発話の統計データを取得しました", "team", team, "talks", teamReport.Total.Talks, "whispers", teamReport.Total.Whispers, "length_mean", teamReport.Total.LengthMean, "vocabulary", teamReport.Total.Vocabulary, "claims", teamReport.Total.Claims)
+		report.Teams = append(report.Teams, teamReport)
+	}
+	slices.SortFunc(report.Teams, func(a, b TeamTalkReport) int {
+		return strings.Compare(a.Team, b.Team)
+	})
+	return report
+}
+
+func (r *TalkReport) Export(format string, path string) error {
+	if path == "" {
+		path = "talk_report." + format
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch format {
+	case RF_CSV:
+		err = r.exportCSV(path)
+	case RF_JSON:
+		var data []byte
+		data, err = json.MarshalIndent(r, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+	case RF_HTML:
+		err = r.exportHTML(path)
+	default:
+		return errors.New("不明なレポート形式です: " + format)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("レポートを出力しました", "format", format, "path", path)
+	return nil
+}
+
+func (r *TalkReport) exportCSV(path string) error {
+	header := []string{"team", "role", "talks", "whispers", "skips", "overs", "skip_rate", "over_rate", "length_mean", "length_p50", "length_p90", "length_max", "mentions", "mention_rate", "vocabulary"}
+	for _, role := range claimRoles {
+		header = append(header, "claim_"+strings.ToLower(role.Name))
+	}
+	records := [][]string{header}
+	for _, team := range r.Teams {
+		rows := append(slices.Clone(team.Roles), team.Total)
+		for _, stats := range rows {
+			name := stats.Role
+			if name == "" {
+				name = "ALL"
+			}
+			record := []string{
+				team.Team,
+				name,
+				strconv.Itoa(stats.Talks),
+				strconv.Itoa(stats.Whispers),
+				strconv.Itoa(stats.Skips),
+				strconv.Itoa(stats.Overs),
+				formatRate(stats.SkipRate),
+				formatRate(stats.OverRate),
+				strconv.FormatFloat(stats.LengthMean, 'f', 1, 64),
+				strconv.FormatInt(stats.LengthP50, 10),
+				strconv.FormatInt(stats.LengthP90, 10),
+				strconv.FormatInt(stats.LengthMax, 10),
+				strconv.Itoa(stats.Mentions),
+				formatRate(stats.MentionRate),
+				strconv.Itoa(stats.Vocabulary),
+			}
+			for _, role := range claimRoles {
+				record = append(record, strconv.Itoa(stats.Claims[role.Name]))
+			}
+			records = append(records, record)
+		}
+	}
+	return writeCSV(path, records)
+}
+
+var talkReportTemplate = newReportTemplate("talk_report", `<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>aiwolf-nlp-server talk report</title>
+{{template "style"}}
+</head>
+<body>
+<h1>Talk statistics</h1>
+<p>Generated at {{.Report.GeneratedAt.Format "2006-01-02 15:04:05"}}</p>
+<table>
+<tr><th>Team</th><th>Role</th><th>Talks</th><th>Whispers</th><th>Skip rate</th><th>Over rate</th><th>Mean length</th><th>p50</th><th>p90</th><th>Max</th><th>Mentions</th><th>Vocabulary</th>{{range .Roles}}<th>{{.}} claims</th>{{end}}</tr>
+{{- range .Report.Teams}}
+{{- $team := .Team}}
+{{- range .Roles}}
+<tr><td class="name">{{$team}}</td><td class="name">{{.Role}}</td><td>{{.Talks}}</td><td>{{.Whispers}}</td><td>{{percent .SkipRate}}</td><td>{{percent .OverRate}}</td><td>{{printf "%.1f" .LengthMean}}</td><td>{{.LengthP50}}</td><td>{{.LengthP90}}</td><td>{{.LengthMax}}</td><td>{{.Mentions}}</td><td>{{.Vocabulary}}</td>{{$claims := .Claims}}{{range $.Roles}}<td>{{index $claims .}}</td>{{end}}</tr>
+{{- end}}
+{{- with .Total}}
+<tr class="total"><td class="name">{{$team}}</td><td class="name">ALL</td><td>{{.Talks}}</td><td>{{.Whispers}}</td><td>{{percent .SkipRate}}</td><td>{{percent .OverRate}}</td><td>{{printf "%.1f" .LengthMean}}</td><td>{{.LengthP50}}</td><td>{{.LengthP90}}</td><td>{{.LengthMax}}</td><td>{{.Mentions}}</td><td>{{.Vocabulary}}</td>{{$claims := .Claims}}{{range $.Roles}}<td>{{index $claims .}}</td>{{end}}</tr>
+{{- end}}
+{{- end}}
+</table>
+</body>
+</html>
+`)
+
+func (r *TalkReport) exportHTML(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	roles := make([]string, 0, len(claimRoles))
+	for _, role := range claimRoles {
+		roles = append(roles, role.Name)
+	}
+	return talkReportTemplate.Execute(file, map[string]any{
+		"Report": r,
+		"Roles":  roles,
+	})
+}
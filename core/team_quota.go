@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+type TeamQuota struct {
+	maxConnections  int
+	maxGamesPerHour int
+	maxGamesPerDay  int
+	mu              sync.Mutex
+	connections     map[string]int
+	gameStarts      map[string][]time.Time
+}
+
+type TeamQuotaStatus struct {
+	Connections   int `json:"connections"`
+	GamesLastHour int `json:"games_last_hour"`
+	GamesToday    int `json:"games_today"`
+}
+
+func NewTeamQuota(config model.Config) *TeamQuota {
+	return &TeamQuota{
+		maxConnections:  config.Server.Quota.MaxConnectionsPerTeam,
+		maxGamesPerHour: config.Server.Quota.MaxGamesPerHour,
+		maxGamesPerDay:  config.Server.Quota.MaxGamesPerDay,
+		connections:     make(map[string]int),
+		gameStarts:      make(map[string][]time.Time),
+	}
+}
+
+func (tq *TeamQuota) Check(team string) error {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	now := time.Now()
+	tq.prune(team, now)
+	if tq.maxConnections > 0 && tq.connections[team] >= tq.maxConnections {
+		return fmt.Errorf("connection limit exceeded: %d/%d", tq.connections[team], tq.maxConnections)
+	}
+	if tq.maxGamesPerHour > 0 {
+		if count := tq.countSince(team, now.Add(-time.Hour)); count >= tq.maxGamesPerHour {
+			return fmt.Errorf("hourly game limit exceeded: %d/%d", count, tq.maxGamesPerHour)
+		}
+	}
+	if tq.maxGamesPerDay > 0 {
+		if count := tq.countSince(team, startOfDay(now)); count >= tq.maxGamesPerDay {
+			return fmt.Errorf("daily game quota exceeded: %d/%d", count, tq.maxGamesPerDay)
+		}
+	}
+	return nil
+}
+
+func (tq *TeamQuota) AddConnection(team string) {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	tq.connections[team]++
+}
+
+func (tq *TeamQuota) RemoveConnection(team string) {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	tq.connections[team]--
+	if tq.connections[team] <= 0 {
+		delete(tq.connections, team)
+	}
+}
+
+func (tq *TeamQuota) RecordGame(team string) {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	now := time.Now()
+	tq.prune(team, now)
+	tq.gameStarts[team] = append(tq.gameStarts[team], now)
+	slog.Info("チームのゲーム数を記録しました", "team", team, "today", tq.countSince(team, startOfDay(now)))
+}
+
+func (tq *TeamQuota) Status() map[string]TeamQuotaStatus {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	now := time.Now()
+	status := make(map[string]TeamQuotaStatus)
+	for team := range tq.connections {
+		status[team] = TeamQuotaStatus{}
+	}
+	for team := range tq.gameStarts {
+		status[team] = TeamQuotaStatus{}
+	}
+	for team := range status {
+		tq.prune(team, now)
+		status[team] = TeamQuotaStatus{
+			Connections:   tq.connections[team],
+			GamesLastHour: tq.countSince(team, now.Add(-time.Hour)),
+			GamesToday:    tq.countSince(team, startOfDay(now)),
+		}
+	}
+	return status
+}
+
+func (tq *TeamQuota) countSince(team string, since time.Time) int {
+	count := 0
+	for _, start := range tq.gameStarts[team] {
+		if !start.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
+func (tq *TeamQuota) prune(team string, now time.Time) {
+	threshold := startOfDay(now)
+	if hourAgo := now.Add(-time.Hour); hourAgo.Before(threshold) {
+		threshold = hourAgo
+	}
+	starts := tq.gameStarts[team]
+	idx := 0
+	for idx < len(starts) && starts[idx].Before(threshold) {
+		idx++
+	}
+	if idx == len(starts) {
+		delete(tq.gameStarts, team)
+		return
+	}
+	tq.gameStarts[team] = starts[idx:]
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -4,25 +4,37 @@ import (
 	"errors"
 	"log/slog"
 	"math/rand/v2"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/gorilla/websocket"
 )
 
 type WaitingRoom struct {
-	agentCount  int
-	selfMatch   bool
-	connections sync.Map
+	agentCount      int
+	policy          model.MatchingPolicy
+	maxSeatsPerTeam int
+	fallbackTimeout time.Duration
+	houseTeams      []string
+	connections     sync.Map
+	mu              sync.Mutex
 }
 
 func NewWaitingRoom(config model.Config) *WaitingRoom {
 	return &WaitingRoom{
-		agentCount: config.Game.AgentCount,
-		selfMatch:  config.Matching.SelfMatch,
+		agentCount:      config.Game.AgentCount,
+		policy:          model.MatchingPolicyFromConfig(config.Matching),
+		maxSeatsPerTeam: config.Matching.MaxSeatsPerTeam,
+		fallbackTimeout: config.Matching.FallbackTimeout,
+		houseTeams:      config.Matching.HouseTeams,
 	}
 }
 
 func (wr *WaitingRoom) AddConnection(team string, connection model.Connection) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
 	value, _ := wr.connections.LoadOrStore(team, []model.Connection{})
 	connections := value.([]model.Connection)
 
@@ -32,7 +44,36 @@ func (wr *WaitingRoom) AddConnection(team string, connection model.Connection) {
 	slog.Info("新しいクライアントが待機部屋に追加されました", "team", team, "remote_addr", connection.Conn.RemoteAddr().String())
 }
 
+func (wr *WaitingRoom) RemoveClosedConnections() []model.Connection {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	var removed []model.Connection
+	wr.connections.Range(func(key, value any) bool {
+		team := key.(string)
+		conns := value.([]model.Connection)
+		alive := make([]model.Connection, 0, len(conns))
+		for _, conn := range conns {
+			if err := conn.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				conn.Conn.Close()
+				removed = append(removed, conn)
+				slog.Info("切断されたクライアントを待機部屋から削除しました", "team", team, "original_name", conn.OriginalName, "error", err)
+				continue
+			}
+			alive = append(alive, conn)
+		}
+		if len(alive) == 0 {
+			wr.connections.Delete(team)
+		} else if len(alive) != len(conns) {
+			wr.connections.Store(team, alive)
+		}
+		return true
+	})
+	return removed
+}
+
 func (wr *WaitingRoom) GetConnectionsWithMatchOptimizer(matches []map[model.Role][]string) (map[model.Role][]model.Connection, error) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
 	var roleMapConns = make(map[model.Role][]model.Connection)
 
 	if len(matches) == 0 {
@@ -92,68 +133,155 @@ func (wr *WaitingRoom) GetConnectionsWithMatchOptimizer(matches []map[model.Role
 }
 
 func (wr *WaitingRoom) GetConnections() ([]model.Connection, error) {
-	connections := []model.Connection{}
-	ready := false
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
 
-	if wr.selfMatch {
-		wr.connections.Range(func(key, value any) bool {
-			team := key.(string)
-			conns := value.([]model.Connection)
+	var connections []model.Connection
+	switch wr.policy {
+	case model.MP_SELF:
+		connections = wr.takeSelfMatch()
+	case model.MP_CROSS:
+		connections = wr.takeCrossMatch(1)
+	case model.MP_MIXED:
+		connections = wr.takeCrossMatch(max(wr.maxSeatsPerTeam, 1))
+	case model.MP_SELF_FALLBACK:
+		connections = wr.takeCrossMatch(1)
+		if connections == nil {
+			connections = wr.takeFallbackMatch(wr.fallbackTimeout)
+		}
+	case model.MP_HOUSE:
+		connections = wr.takeHouseMatch()
+	}
 
-			if len(conns) >= wr.agentCount {
-				connections = append(connections, conns[:wr.agentCount]...)
+	if connections == nil {
+		return nil, errors.New("待機部屋内の接続が不足しています")
+	}
+	slog.Info("マッチの接続を取得しました", "policy", wr.policy)
+	return connections, nil
+}
 
-				if len(conns) > wr.agentCount {
-					wr.connections.Store(team, conns[wr.agentCount:])
-				} else {
-					wr.connections.Delete(team)
-				}
-				ready = true
-				return false
-			}
-			return true
-		})
-	} else {
-		var teams []string
-		wr.connections.Range(func(key, value any) bool {
-			team := key.(string)
-			conns := value.([]model.Connection)
-			if len(conns) > 0 {
-				teams = append(teams, team)
-			}
+func (wr *WaitingRoom) takeSelfMatch() []model.Connection {
+	var connections []model.Connection
+	wr.connections.Range(func(key, value any) bool {
+		team := key.(string)
+		conns := value.([]model.Connection)
+
+		if len(conns) < wr.agentCount {
 			return true
-		})
+		}
+		connections = append(connections, conns[:wr.agentCount]...)
+		wr.consume(team, conns, wr.agentCount)
+		return false
+	})
+	return connections
+}
 
-		if len(teams) >= wr.agentCount {
-			rand.Shuffle(len(teams), func(i, j int) {
-				teams[i], teams[j] = teams[j], teams[i]
-			})
+// 待機時間を過ぎても対戦相手が揃わない場合は、待機中のチームの残りのエージェントで席を埋める
+func (wr *WaitingRoom) takeFallbackMatch(timeout time.Duration) []model.Connection {
+	teams, available := wr.collectTeams(func(team string) bool { return true }, wr.agentCount)
+	if available < wr.agentCount {
+		return nil
+	}
+	slices.SortFunc(teams, func(a, b string) int {
+		return wr.oldest(a).Compare(wr.oldest(b))
+	})
+	if time.Since(wr.oldest(teams[0])) < timeout {
+		return nil
+	}
+	return wr.takeRoundRobin(teams, wr.agentCount, wr.agentCount)
+}
 
-			for _, team := range teams[:wr.agentCount] {
-				value, exists := wr.connections.Load(team)
-				if !exists {
-					continue
-				}
-				conns := value.([]model.Connection)
-				if len(conns) == 0 {
-					continue
-				}
+func (wr *WaitingRoom) takeCrossMatch(maxSeats int) []model.Connection {
+	teams, available := wr.collectTeams(func(team string) bool { return true }, maxSeats)
+	if available < wr.agentCount {
+		return nil
+	}
+	rand.Shuffle(len(teams), func(i, j int) {
+		teams[i], teams[j] = teams[j], teams[i]
+	})
+	return wr.takeRoundRobin(teams, maxSeats, wr.agentCount)
+}
 
-				connections = append(connections, conns[0])
+func (wr *WaitingRoom) takeHouseMatch() []model.Connection {
+	players, _ := wr.collectTeams(func(team string) bool { return !slices.Contains(wr.houseTeams, team) }, 1)
+	if len(players) == 0 {
+		return nil
+	}
+	slices.SortFunc(players, func(a, b string) int {
+		return wr.oldest(a).Compare(wr.oldest(b))
+	})
+	player := players[0]
+	value, _ := wr.connections.Load(player)
+	seats := min(max(wr.maxSeatsPerTeam, 1), len(value.([]model.Connection)), wr.agentCount)
 
-				if len(conns) > 1 {
-					wr.connections.Store(team, conns[1:])
-				} else {
-					wr.connections.Delete(team)
-				}
+	houses, available := wr.collectTeams(func(team string) bool { return slices.Contains(wr.houseTeams, team) }, wr.agentCount)
+	if available < wr.agentCount-seats {
+		return nil
+	}
+	connections := wr.takeRoundRobin([]string{player}, seats, seats)
+	return append(connections, wr.takeRoundRobin(houses, wr.agentCount, wr.agentCount-seats)...)
+}
+
+func (wr *WaitingRoom) collectTeams(filter func(team string) bool, maxSeats int) ([]string, int) {
+	var teams []string
+	available := 0
+	wr.connections.Range(func(key, value any) bool {
+		team := key.(string)
+		conns := value.([]model.Connection)
+		if len(conns) > 0 && filter(team) {
+			teams = append(teams, team)
+			available += min(len(conns), maxSeats)
+		}
+		return true
+	})
+	return teams, available
+}
+
+func (wr *WaitingRoom) takeRoundRobin(teams []string, maxSeats int, count int) []model.Connection {
+	connections := []model.Connection{}
+	taken := make(map[string]int)
+	for len(connections) < count {
+		progressed := false
+		for _, team := range teams {
+			if len(connections) >= count || taken[team] >= maxSeats {
+				continue
+			}
+			value, exists := wr.connections.Load(team)
+			if !exists {
+				continue
+			}
+			conns := value.([]model.Connection)
+			if len(conns) == 0 {
+				continue
 			}
-			ready = true
+			connections = append(connections, conns[0])
+			wr.consume(team, conns, 1)
+			taken[team]++
+			progressed = true
+		}
+		if !progressed {
+			break
 		}
 	}
+	return connections
+}
 
-	if !ready {
-		return nil, errors.New("待機部屋内の接続が不足しています")
+func (wr *WaitingRoom) consume(team string, conns []model.Connection, count int) {
+	if len(conns) > count {
+		wr.connections.Store(team, conns[count:])
+	} else {
+		wr.connections.Delete(team)
 	}
-	slog.Info("マッチの接続を取得しました")
-	return connections, nil
+}
+
+func (wr *WaitingRoom) oldest(team string) time.Time {
+	value, exists := wr.connections.Load(team)
+	if !exists {
+		return time.Time{}
+	}
+	conns := value.([]model.Connection)
+	if len(conns) == 0 {
+		return time.Time{}
+	}
+	return conns[0].ConnectedAt
 }
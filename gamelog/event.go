@@ -0,0 +1,146 @@
+package gamelog
+
+import (
+	"strconv"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+const Version = 2
+
+type EventType string
+
+const (
+	E_STATUS      EventType = "status"
+	E_TALK        EventType = "talk"
+	E_WHISPER     EventType = "whisper"
+	E_VOTE        EventType = "vote"
+	E_ATTACK_VOTE EventType = "attackVote"
+	E_EXECUTE     EventType = "execute"
+	E_DIVINE      EventType = "divine"
+	E_GUARD       EventType = "guard"
+	E_ATTACK      EventType = "attack"
+	E_RESULT      EventType = "result"
+)
+
+type Event interface {
+	GetDay() int
+	GetType() EventType
+	fields() []string
+}
+
+type StatusEvent struct {
+	Day          int
+	Idx          int
+	Role         model.Role
+	Status       model.Status
+	OriginalName string
+	GameName     string
+}
+
+type TalkEvent struct {
+	Day      int
+	Whisper  bool
+	Idx      int
+	Turn     int
+	AgentIdx int
+	Text     string
+}
+
+type VoteEvent struct {
+	Day       int
+	Attack    bool
+	AgentIdx  int
+	TargetIdx int
+}
+
+type ExecuteEvent struct {
+	Day      int
+	AgentIdx int
+	Role     model.Role
+}
+
+type DivineEvent struct {
+	Day       int
+	AgentIdx  int
+	TargetIdx int
+	Result    model.Species
+}
+
+type GuardEvent struct {
+	Day       int
+	AgentIdx  int
+	TargetIdx int
+	Role      model.Role
+}
+
+type AttackEvent struct {
+	Day       int
+	TargetIdx int
+	Success   bool
+}
+
+type ResultEvent struct {
+	Day        int
+	Villagers  int
+	Werewolves int
+	WinSide    model.Team
+}
+
+func (e StatusEvent) GetDay() int        { return e.Day }
+func (e StatusEvent) GetType() EventType { return E_STATUS }
+func (e StatusEvent) fields() []string {
+	return []string{strconv.Itoa(e.Idx), e.Role.Name, e.Status.String(), e.OriginalName, e.GameName}
+}
+
+func (e TalkEvent) GetDay() int { return e.Day }
+func (e TalkEvent) GetType() EventType {
+	if e.Whisper {
+		return E_WHISPER
+	}
+	return E_TALK
+}
+func (e TalkEvent) fields() []string {
+	return []string{strconv.Itoa(e.Idx), strconv.Itoa(e.Turn), strconv.Itoa(e.AgentIdx), e.Text}
+}
+
+func (e VoteEvent) GetDay() int { return e.Day }
+func (e VoteEvent) GetType() EventType {
+	if e.Attack {
+		return E_ATTACK_VOTE
+	}
+	return E_VOTE
+}
+func (e VoteEvent) fields() []string {
+	return []string{strconv.Itoa(e.AgentIdx), strconv.Itoa(e.TargetIdx)}
+}
+
+func (e ExecuteEvent) GetDay() int        { return e.Day }
+func (e ExecuteEvent) GetType() EventType { return E_EXECUTE }
+func (e ExecuteEvent) fields() []string {
+	return []string{strconv.Itoa(e.AgentIdx), e.Role.Name}
+}
+
+func (e DivineEvent) GetDay() int        { return e.Day }
+func (e DivineEvent) GetType() EventType { return E_DIVINE }
+func (e DivineEvent) fields() []string {
+	return []string{strconv.Itoa(e.AgentIdx), strconv.Itoa(e.TargetIdx), string(e.Result)}
+}
+
+func (e GuardEvent) GetDay() int        { return e.Day }
+func (e GuardEvent) GetType() EventType { return E_GUARD }
+func (e GuardEvent) fields() []string {
+	return []string{strconv.Itoa(e.AgentIdx), strconv.Itoa(e.TargetIdx), e.Role.Name}
+}
+
+func (e AttackEvent) GetDay() int        { return e.Day }
+func (e AttackEvent) GetType() EventType { return E_ATTACK }
+func (e AttackEvent) fields() []string {
+	return []string{strconv.Itoa(e.TargetIdx), strconv.FormatBool(e.Success)}
+}
+
+func (e ResultEvent) GetDay() int        { return e.Day }
+func (e ResultEvent) GetType() EventType { return E_RESULT }
+func (e ResultEvent) fields() []string {
+	return []string{strconv.Itoa(e.Villagers), strconv.Itoa(e.Werewolves), string(e.WinSide)}
+}
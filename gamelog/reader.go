@@ -0,0 +1,195 @@
+package gamelog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+type Reader struct {
+	Version int
+	csv     *csv.Reader
+	lines   *bufio.Scanner
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	reader := &Reader{Version: 1}
+	head, err := br.Peek(len(headerPrefix))
+	if err == nil && string(head) == headerPrefix {
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, headerPrefix)))
+		if err != nil {
+			return nil, fmt.Errorf("ゲームログのバージョンが不正です: %w", err)
+		}
+		if version > Version {
+			return nil, fmt.Errorf("未対応のゲームログのバージョンです: %d", version)
+		}
+		reader.Version = version
+	}
+	if reader.Version >= 2 {
+		reader.csv = csv.NewReader(br)
+		reader.csv.FieldsPerRecord = -1
+		reader.csv.Comment = '#'
+	} else {
+		reader.lines = bufio.NewScanner(br)
+		reader.lines.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	}
+	return reader, nil
+}
+
+func (r *Reader) Read() (Event, error) {
+	for {
+		record, err := r.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		if len(record) == 0 || (len(record) == 1 && record[0] == "") {
+			continue
+		}
+		return parseRecord(record)
+	}
+}
+
+func (r *Reader) ReadAll() ([]Event, error) {
+	events := []Event{}
+	for {
+		event, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+}
+
+func (r *Reader) readRecord() ([]string, error) {
+	if r.csv != nil {
+		return r.csv.Read()
+	}
+	if !r.lines.Scan() {
+		if err := r.lines.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	line := r.lines.Text()
+	values := strings.Split(line, ",")
+	if len(values) > 6 && (values[1] == string(E_TALK) || values[1] == string(E_WHISPER)) {
+		values = append(values[:5], strings.Join(values[5:], ","))
+	}
+	return values, nil
+}
+
+func parseRecord(record []string) (Event, error) {
+	if len(record) < 2 {
+		return nil, fmt.Errorf("ゲームログの行が不正です: %v", record)
+	}
+	day, err := strconv.Atoi(record[0])
+	if err != nil {
+		return nil, fmt.Errorf("ゲームログの日付が不正です: %w", err)
+	}
+	values := record[2:]
+	ints := func(idxs ...int) ([]int, error) {
+		result := make([]int, len(idxs))
+		for i, idx := range idxs {
+			value, err := strconv.Atoi(values[idx])
+			if err != nil {
+				return nil, fmt.Errorf("ゲームログの数値が不正です: %w", err)
+			}
+			result[i] = value
+		}
+		return result, nil
+	}
+	arity := map[EventType]int{
+		E_STATUS:      4,
+		E_TALK:        4,
+		E_WHISPER:     4,
+		E_VOTE:        2,
+		E_ATTACK_VOTE: 2,
+		E_EXECUTE:     2,
+		E_DIVINE:      3,
+		E_GUARD:       3,
+		E_ATTACK:      2,
+		E_RESULT:      3,
+	}
+	eventType := EventType(record[1])
+	required, exists := arity[eventType]
+	if !exists {
+		return nil, fmt.Errorf("不明なイベントです: %s", record[1])
+	}
+	if len(values) < required {
+		return nil, fmt.Errorf("イベントの項目数が不足しています: %s", record[1])
+	}
+
+	switch eventType {
+	case E_STATUS:
+		n, err := ints(0)
+		if err != nil {
+			return nil, err
+		}
+		event := StatusEvent{Day: day, Idx: n[0], Role: model.RoleFromString(values[1]), Status: model.Status(values[2]), OriginalName: values[3]}
+		if len(values) > 4 {
+			event.GameName = values[4]
+		}
+		return event, nil
+	case E_TALK, E_WHISPER:
+		n, err := ints(0, 1, 2)
+		if err != nil {
+			return nil, err
+		}
+		return TalkEvent{Day: day, Whisper: eventType == E_WHISPER, Idx: n[0], Turn: n[1], AgentIdx: n[2], Text: values[3]}, nil
+	case E_VOTE, E_ATTACK_VOTE:
+		n, err := ints(0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return VoteEvent{Day: day, Attack: eventType == E_ATTACK_VOTE, AgentIdx: n[0], TargetIdx: n[1]}, nil
+	case E_EXECUTE:
+		n, err := ints(0)
+		if err != nil {
+			return nil, err
+		}
+		return ExecuteEvent{Day: day, AgentIdx: n[0], Role: model.RoleFromString(values[1])}, nil
+	case E_DIVINE:
+		n, err := ints(0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return DivineEvent{Day: day, AgentIdx: n[0], TargetIdx: n[1], Result: model.SpeciesFromString(values[2])}, nil
+	case E_GUARD:
+		n, err := ints(0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return GuardEvent{Day: day, AgentIdx: n[0], TargetIdx: n[1], Role: model.RoleFromString(values[2])}, nil
+	case E_ATTACK:
+		n, err := ints(0)
+		if err != nil {
+			return nil, err
+		}
+		success, err := strconv.ParseBool(values[1])
+		if err != nil {
+			return nil, fmt.Errorf("襲撃結果が不正です: %w", err)
+		}
+		return AttackEvent{Day: day, TargetIdx: n[0], Success: success}, nil
+	case E_RESULT:
+		n, err := ints(0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return ResultEvent{Day: day, Villagers: n[0], Werewolves: n[1], WinSide: model.TeamFromString(values[2])}, nil
+	}
+	return nil, fmt.Errorf("不明なイベントです: %s", record[1])
+}
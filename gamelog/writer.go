@@ -0,0 +1,22 @@
+package gamelog
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+const headerPrefix = "#version="
+
+func Header() string {
+	return headerPrefix + strconv.Itoa(Version)
+}
+
+func Format(event Event) string {
+	record := append([]string{strconv.Itoa(event.GetDay()), string(event.GetType())}, event.fields()...)
+	var builder strings.Builder
+	writer := csv.NewWriter(&builder)
+	writer.Write(record)
+	writer.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,329 @@
+package locale
+
+var catalogEN = map[string]string{
+	"INITIALIZEリクエストの記録が不足しています":                        "Not enough INITIALIZE requests were recorded",
+	"JSONLファイルにブロードキャストを保存しました":                         "Saved broadcast to JSONL file",
+	"JSONログが無効になっています":                                  "JSON logging is disabled",
+	"JSONログのパースに失敗しました":                                 "Failed to parse JSON log",
+	"JSONログの保存に失敗しました":                                  "Failed to save JSON log",
+	"JSONログの書き込みに失敗しました":                                "Failed to write JSON log",
+	"JSONログの読み込みに失敗しました":                                "Failed to read JSON log",
+	"JSONログファイルの作成に失敗しました":                              "Failed to create JSON log file",
+	"NAMEパケットの作成に失敗しました":                                "Failed to create NAME packet",
+	"NAMEパケットの送信に失敗しました":                                "Failed to send NAME packet",
+	"NAMEパケットを送信しました":                                   "Sent NAME packet",
+	"NAMEリクエストのレスポンスを受信しました":                            "Received response to NAME request",
+	"NAMEリクエストのレスポンス受信がタイムアウトしました":                      "Timed out waiting for response to NAME request",
+	"NAMEリクエストのレスポンス受信に失敗しました":                          "Failed to receive response to NAME request",
+	"NAMEリクエストの受信に失敗しました":                               "Failed to receive NAME request",
+	"WAVからセグメントへの変換に失敗しました":                             "Failed to convert WAV to segments",
+	"[Talk] CountInWordとCountSpacesを両方有効にすることはできません":    "[Talk] CountInWord and CountSpaces cannot both be enabled",
+	"[Whisper] CountInWordとCountSpacesを両方有効にすることはできません": "[Whisper] CountInWord and CountSpaces cannot both be enabled",
+	"より良い解が見つかりました":                                     "Found a better solution",
+	"アナウンスの事前生成に失敗しました":                                 "Failed to pre-render announcement",
+	"アナウンスを事前生成しました":                                    "Pre-rendered announcement",
+	"アーカイブの作成に失敗しました":                                   "Failed to create archive",
+	"アーカイブの削除に失敗しました":                                   "Failed to delete archive",
+	"アーカイブの取得に失敗しました":                                   "Failed to list archives",
+	"アーカイブの読み込みに失敗しました":                                 "Failed to read archive",
+	"アーカイブディレクトリの作成に失敗しました":                             "Failed to create archive directory",
+	"アーカイブ内のファイルの展開に失敗しました":                             "Failed to extract file from archive",
+	"イベントの項目数が不足しています":                                  "Not enough fields in event",
+	"エラーが多発したため、ゲームを終了します":                              "Ending the game because too many errors occurred",
+	"エラーが発生したチームを代替チームに置換しました":                          "Replaced the team that caused errors with a substitute team",
+	"エントリのJSON化に失敗しました":                                 "Failed to encode entry as JSON",
+	"エージェントにエラーが発生しているため、リクエストを送信できません":                 "Cannot send request because the agent has an error",
+	"エージェントのゲーム内名を特定できませんでした":                           "Could not determine the in-game names of the agents",
+	"エージェントをクローズしました":                                   "Closed agent",
+	"エージェントを作成しました":                                     "Created agent",
+	"エージェント情報のJSON化に失敗しました":                             "Failed to encode agent information as JSON",
+	"エージェント数が2未満のため、通信を行いません":                           "Skipping communication because there are fewer than 2 agents",
+	"オーディオクエリエラー":                                       "Audio query error",
+	"オーディオクエリリクエスト作成に失敗しました":                            "Failed to create audio query request",
+	"オーディオクエリ読み取りに失敗しました":                               "Failed to read audio query",
+	"オーディオクエリ送信に失敗しました":                                 "Failed to send audio query",
+	"カスタムプロフィールのアバターがエージェント数より少ないです":                    "Custom profile avatars are fewer than the number of agents",
+	"カスタムプロフィールの人数がエージェント数より少ないです":                      "Custom profiles are fewer than the number of agents",
+	"キューからゲームを開始します":                                    "Starting game from the queue",
+	"クライアントから強制スキップが指定されたため、発言をスキップに置換しました":             "Replaced talk with skip because the client requested a forced skip",
+	"クライアントが接続しました":                                     "Client connected",
+	"クライアントのアップグレードに失敗しました":                             "Failed to upgrade client connection",
+	"クライアントの接続に失敗しました":                                  "Failed to connect client",
+	"クライアントの接続を切断しました":                                  "Disconnected client",
+	"クレームの取得に失敗しました":                                    "Failed to get claims",
+	"クローズメッセージの送信に失敗しました":                               "Failed to send close message",
+	"ゲームが終了しました":                                        "The game has ended",
+	"ゲームが開始されました":                                       "The game has started",
+	"ゲームをキューに追加しました":                                    "Added game to the queue",
+	"ゲームをキューに追加できないため、接続を切断します":                         "Closing connections because the game could not be added to the queue",
+	"ゲームを作成しました":                                        "Created game",
+	"ゲームを開始します":                                         "Starting game",
+	"ゲームインデックスの更新に失敗しました":                               "Failed to update games index",
+	"ゲームインデックスの行のパースに失敗しました":                            "Failed to parse games index line",
+	"ゲームインデックスの読み込みに失敗しました":                             "Failed to load games index",
+	"ゲームキューが上限に達しています":                                  "The game queue is full",
+	"ゲームキューが上限に達しているため、接続を待機させます":                       "Keeping connections waiting because the game queue is full",
+	"ゲームサマリーのJSON化に失敗しました":                              "Failed to encode game summary as JSON",
+	"ゲームサマリーの保存に失敗しました":                                 "Failed to save game summary",
+	"ゲームサマリーを保存しました":                                    "Saved game summary",
+	"ゲームファイルの作成に失敗しました":                                 "Failed to create game file",
+	"ゲームファイルの保存に失敗しました":                                 "Failed to save game file",
+	"ゲームファイルの書き込みに失敗しました":                               "Failed to write game file",
+	"ゲームファイルを保存しました":                                    "Saved game file",
+	"ゲームログが無効になっているため、発話の統計データを分析できません":                 "Cannot analyze talk statistics because game logging is disabled",
+	"ゲームログのバージョンが不正です":                                  "Invalid game log version",
+	"ゲームログのパースに失敗しました":                                  "Failed to parse game log",
+	"ゲームログの保存に失敗しました":                                   "Failed to save game log",
+	"ゲームログの数値が不正です":                                     "Invalid number in game log",
+	"ゲームログの日付が不正です":                                     "Invalid day in game log",
+	"ゲームログの書き込みに失敗しました":                                 "Failed to write game log",
+	"ゲームログの行が不正です":                                      "Invalid game log line",
+	"ゲームログの読み込みに失敗しました":                                 "Failed to read game log",
+	"ゲームログファイルの作成に失敗しました":                               "Failed to create game log file",
+	"ゲーム一覧のJSON生成に失敗しました":                               "Failed to encode games list as JSON",
+	"ゲーム一覧ファイルの作成に失敗しました":                               "Failed to create games list file",
+	"ゲーム一覧ファイルの初期化に失敗しました":                              "Failed to initialize games list file",
+	"ゲーム一覧ファイルを更新しました":                                  "Updated games list file",
+	"ゲーム設定の作成に失敗しました":                                   "Failed to create game setting",
+	"コマンドの実行に失敗しました":                                    "Failed to execute command",
+	"コマンドの実行に成功しました":                                    "Executed command successfully",
+	"サーバの起動に失敗しました":                                     "Failed to start server",
+	"サーバを起動しました":                                        "Started server",
+	"シグナルを受信したため、新しい接続を受け付けません":                         "Signal received, no longer accepting new connections",
+	"シグナルを受信しました":                                       "Received signal",
+	"スキップ回数が上限に達したため、発言をオーバーに置換しました":                    "Replaced talk with over because the skip limit was reached",
+	"スケジュールされたマッチから削除しました":                              "Removed from scheduled matches",
+	"スケジュールされたマッチがありません":                                "No scheduled matches, adding new ones",
+	"スケジュールされたマッチがないため、新たに追加します":                        "No scheduled matches",
+	"スケジュールされたマッチが見つかりませんでした":                           "Scheduled match not found",
+	"スケジュールされたマッチの接続を取得しました":                            "Acquired connections for scheduled match",
+	"スケジュールされたマッチの重みを設定しました":                            "Set weight of scheduled match",
+	"スケジュールされたマッチ内に不足しているチームがあります":                      "Some teams are missing from the scheduled match",
+	"スケジュールされた役職を取得しました":                                "Acquired scheduled roles",
+	"ストリームを作成しました":                                      "Created stream",
+	"ストリームディレクトリの作成に失敗しました":                             "Failed to create stream directory",
+	"セグメントディレクトリのクリーンアップが完了しました":                        "Finished cleaning up segment directory",
+	"セグメントディレクトリの作成に失敗しました":                             "Failed to create segment directory",
+	"セグメントディレクトリの削除に失敗しました":                             "Failed to remove segment directory",
+	"セグメント再生時間の取得に失敗しました":                               "Failed to get segment duration",
+	"ダイナミックプロフィールの生成をリクエストしました":                         "Requested dynamic profile generation",
+	"ダイナミックプロフィールを生成しました":                               "Generated dynamic profiles",
+	"チームが既に別のインデックスで登録されています":                           "Team is already registered at another index",
+	"チームが既に登録されています":                                    "Team is already registered",
+	"チームのインデックスが不正です":                                   "Invalid team index",
+	"チームのインデックスが範囲外です":                                  "Team index is out of range",
+	"チームのゲーム数を記録しました":                                   "Recorded team game count",
+	"チームの制限を超えているため、接続を拒否します":                           "Rejecting connection because the team limit was exceeded",
+	"チームを置換しました":                                        "Replaced team",
+	"チームを追加しました":                                        "Added team",
+	"チーム名が空です":                                          "Team name is empty",
+	"チーム数が上限に達しているため追加できません":                            "Cannot add team because the team limit was reached",
+	"データセットの出力に失敗しました":                                  "Failed to export dataset",
+	"データセットを出力しました":                                     "Exported dataset",
+	"トーク": "Talk",
+	"トークフェーズを開始します":                       "Starting talk phase",
+	"トークンが有効です":                           "Token is valid",
+	"トークンが無効です":                           "Token is invalid",
+	"トークンの有効期限が切れています":                    "Token has expired",
+	"トークンの検証に失敗しました":                      "Failed to verify token",
+	"パケットのJSON化に失敗しました":                   "Failed to encode packet as JSON",
+	"パケットのインデックスが不正です":                    "Invalid packet index",
+	"パケットの作成に失敗しました":                      "Failed to create packet",
+	"パケットの送信に失敗しました":                      "Failed to send packet",
+	"パケットを送信しました":                         "Sent packet",
+	"ファイルのフラッシュに失敗しました":                   "Failed to flush the file",
+	"ファイルの取得に失敗しました":                      "Failed to get file",
+	"ブロードキャストの購読を開始しました":                  "Started broadcast subscription",
+	"プレイリストの作成に失敗しました":                    "Failed to create playlist",
+	"プレイリストの書き込みに失敗しました":                  "Failed to write playlist",
+	"プレイリストへのセグメント追加に失敗しました":              "Failed to add segment to playlist",
+	"プレイリストディレクトリの作成に失敗しました":              "Failed to create playlist directory",
+	"プロフィールの生成に失敗したため、カスタムプロフィールを使用します":   "Failed to generate profiles, using custom profiles",
+	"ページ番号が不正です":                          "Invalid page number",
+	"マスタープレイリストの書き込みに失敗しました":              "Failed to write master playlist",
+	"マッチの再試行を予約しました":                      "Scheduled match retry",
+	"マッチの接続を取得しました":                       "Acquired connections for match",
+	"マッチオプティマイザが有効ではありません":                "Match optimizer is not enabled",
+	"マッチオプティマイザのパースに失敗しました":               "Failed to parse match optimizer",
+	"マッチオプティマイザの作成に失敗しました":                "Failed to create match optimizer",
+	"マッチオプティマイザの統計データを分析します":              "Analyzing match optimizer statistics",
+	"マッチオプティマイザの読み込みに失敗しました":              "Failed to load match optimizer",
+	"マッチオプティマイザを作成します":                    "Creating match optimizer",
+	"マッチオプティマイザを初期化します":                   "Initializing match optimizer",
+	"マッチング最適化を開始します":                      "Starting match optimization",
+	"マッチ履歴を追加しました":                        "Added match history",
+	"ユニークな名前を生成できませんでした":                  "Could not generate a unique name",
+	"リアルタイムブロードキャスターが有効ではありません":           "Realtime broadcaster is not enabled",
+	"リアルタイムブロードキャスターを初期化しました":             "Initialized realtime broadcaster",
+	"リクエストのレスポンス受信がタイムアウトしました":            "Timed out waiting for response to request",
+	"リクエストの送受信に失敗したため、発言をスキップに置換しました":     "Replaced talk with skip because sending or receiving the request failed",
+	"リプレイが記録と一致しません":                      "Replay does not match the record",
+	"リプレイしたゲームログの読み込みに失敗しました":             "Failed to read replayed game log",
+	"リプレイに失敗しました":                         "Replay failed",
+	"リプレイ用のゲームを作成しました":                    "Created game for replay",
+	"リプレイ結果が記録と一致しました":                    "Replay result matches the record",
+	"リプレイ結果が記録と一致しません":                    "Replay result does not match the record",
+	"レスポンスの受信がタイムアウトしたため、NAMEリクエストを送信します": "Response timed out, sending NAME request",
+	"レスポンスの受信に失敗したため、NAMEリクエストを送信します":     "Failed to receive response, sending NAME request",
+	"レスポンスを受信しました":                        "Received response",
+	"レポートの出力に失敗しました":                      "Failed to export report",
+	"レポートを出力しました":                         "Exported report",
+	"ログサービスの統計データを分析します":                  "Analyzing log service statistics",
+	"ログファイルの取得に失敗しました":                    "Failed to list log files",
+	"ログファイルの圧縮に失敗しました":                    "Failed to compress log file",
+	"ログファイルの読み込みに失敗しました":                  "Failed to read log file",
+	"ログファイルをアーカイブしました":                    "Archived log files",
+	"ログファイルを圧縮しました":                       "Compressed log file",
+	"一致するリクエストがありません":                     "No matching request",
+	"不明なアクションです":                          "Unknown action",
+	"不明なイベントです":                           "Unknown event",
+	"不明な役職名があります":                         "Unknown role name",
+	"不明な言語です":                             "Unknown language",
+	"不明な音声合成エンジンです":                       "Unknown speech synthesis engine",
+	"不正なNAMEリクエストのレスポンスを受信しました":           "Received invalid response to NAME request",
+	"代替可能なチームがありません":                      "No substitute team available",
+	"保持期間または容量の上限を超えたアーカイブを削除しました":        "Removed archives exceeding the retention period or size limit",
+	"全てのゲームが終了しました":                       "All games have finished",
+	"再試行回数が上限に達したため、マッチの重みを0に設定しました":      "Set match weight to 0 because the retry limit was reached",
+	"再開位置のインデックスが不正です":                    "Invalid resume index",
+	"出力ディレクトリの作成に失敗しました":                  "Failed to create output directory",
+	"切断されたクライアントを待機部屋から削除しました":            "Removed disconnected client from the waiting room",
+	"勝利チームが決定したため、ゲームを終了します":              "Ending the game because the winning team was decided",
+	"勝率に有意差があります":                         "Win rates differ significantly",
+	"占い":                                  "Divine",
+	"占いアクションを開始します":                       "Starting divine action",
+	"占いフェーズを終了します":                        "Ending divine phase",
+	"占いフェーズを開始します":                        "Starting divine phase",
+	"占い対象が死亡しているため、占い結果を設定しません":           "Divine target is dead, not setting divine result",
+	"占い対象が自分自身であるため、占い結果を設定しません":          "Divine target is self, not setting divine result",
+	"占い対象が見つからなかったため、占い結果を設定しません":         "Divine target not found, not setting divine result",
+	"占い結果を設定しました":                         "Set divine result",
+	"参加者トークンを検証します":                       "Verifying player token",
+	"取得件数が不正です":                           "Invalid limit",
+	"各役職の理論値を計算しました":                      "Calculated theoretical values for each role",
+	"合成エラー": "Synthesis error",
+	"合成データ読み取りに失敗しました": "Failed to read synthesized data",
+	"合成リクエスト作成に失敗しました": "Failed to create synthesis request",
+	"合成リクエスト送信に失敗しました": "Failed to send synthesis request",
+	"囁き":           "Whisper",
+	"囁きフェーズを開始します": "Starting whisper phase",
+	"圧縮済みログファイルの取得に失敗しました":          "Failed to list compressed log files",
+	"夜セクションのフェーズを実行します":             "Running night phase",
+	"夜セクションを終了します":                  "Ending night section",
+	"夜セクションを開始します":                  "Starting night section",
+	"字幕セグメントの書き込みに失敗しました":           "Failed to write subtitle segment",
+	"字幕プレイリストの作成に失敗しました":            "Failed to create subtitle playlist",
+	"字幕プレイリストの書き込みに失敗しました":          "Failed to write subtitle playlist",
+	"字幕プレイリストへのセグメント追加に失敗しました":      "Failed to add segment to subtitle playlist",
+	"実行対象の日ではないため、フェーズをスキップします":     "Skipping phase because it is not scheduled for this day",
+	"容量の上限を超えた音声合成キャッシュを削除しました":     "Removed speech synthesis cache entry exceeding the size limit",
+	"対応する役職の人数がありません":               "No agents for the corresponding role",
+	"対象エージェントが見つかりません":              "Target agent not found",
+	"対象エージェントを受信しました":               "Received target agent",
+	"強制スキップが指定されたため、発言をスキップに置換しました": "Replaced talk with skip because a forced skip was specified",
+	"役職が取得できませんでした":                 "Could not get role",
+	"待機部屋からの接続の取得に失敗しました":           "Failed to get connections from the waiting room",
+	"待機部屋内の接続が不足しています":              "Not enough connections in the waiting room",
+	"応答時間の統計データを分析します":              "Analyzing response latency statistics",
+	"応答時間の統計データを取得しました":             "Retrieved response latency statistics",
+	"投票":        "Vote",
+	"投票を受信しました": "Received vote",
+	"投票アクションを開始します":                         "Starting vote action",
+	"投票対象が死亡しているため、投票を無視します":                "Vote target is dead, ignoring vote",
+	"指定されたインデックスのパケットが見つかりません":              "Packet with the specified index not found",
+	"接続が閉じられました":                            "Connection closed",
+	"文字数が0のため、発言をオーバーに置換しました":               "Replaced talk with over because it was empty",
+	"新しいクライアントが待機部屋に追加されました":                "Added new client to the waiting room",
+	"日付が進みました":                              "Day advanced",
+	"昼セクションのフェーズを開始します":                     "Starting day phase",
+	"昼セクションを終了します":                          "Ending day section",
+	"昼セクションを開始します":                          "Starting day section",
+	"最大日数に達したため、ゲームを終了します":                  "Ending the game because the maximum day was reached",
+	"最良の解を採用します":                            "Adopting the best solution",
+	"最適なマッチングが見つかりませんでした":                   "No optimal matching found",
+	"未対応のゲームログのバージョンです":                     "Unsupported game log version",
+	"環境変数の読み込みに失敗しました":                      "Failed to load environment variables",
+	"発言がオーバーであるため、残り発言回数を0にしました":            "Set remaining talk count to 0 because the talk was over",
+	"発言がオーバーもしくはスキップではないため、スキップ回数をリセットしました": "Reset skip count because the talk was neither over nor skip",
+	"発言が最大文字数を超えたため、切り捨てました":                "Truncated talk because it exceeded the maximum length",
+	"発言をスキップしました":                           "Skipped talk",
+	"発言を受信しました":                             "Received talk",
+	"発話の統計データを分析します":                        "Analyzing talk statistics",
+	"発話の統計データを取得しました":                       "Retrieved talk statistics",
+	"登録されていないチームです":                         "Team is not registered",
+	"登録チームが別のインデックスに割り当てられています":             "Registered team is assigned to another index",
+	"登録チームが重複しています":                         "Duplicate registered teams",
+	"登録チームを追加しました":                          "Added registered team",
+	"登録チーム数が保存されたチーム数と一致しません":               "The number of registered teams does not match the saved team count",
+	"登録チーム数とチーム数が一致しません":                    "Number of registered teams does not match the number of teams",
+	"登録済みチームを取得しました":                        "Retrieved registered teams",
+	"空の字幕セグメントの書き込みに失敗しました":                 "Failed to write the empty subtitle segment",
+	"管理者トークンを検証します":                         "Verifying admin token",
+	"終了": "End",
+	"終了した役職を取得しました":            "Retrieved finished roles",
+	"結果が取得できませんでした":            "Could not get result",
+	"統計データを取得しました":             "Retrieved statistics",
+	"自己投票は許可されていないため、投票を無視します": "Self-voting is not allowed, ignoring vote",
+	"襲撃":           "Attack",
+	"襲撃フェーズを終了します": "Ending attack phase",
+	"襲撃フェーズを開始します": "Starting attack phase",
+	"襲撃対象がいないため、襲撃結果を設定しません": "No attack target, not setting attack result",
+	"襲撃投票": "Attack vote",
+	"襲撃投票アクションを開始します":                      "Starting attack vote action",
+	"襲撃結果が不正です":                            "Invalid attack result",
+	"襲撃結果を設定しました":                          "Set attack result",
+	"観戦者の接続のアップグレードに失敗しました":                "Failed to upgrade spectator connection",
+	"解説者向けゲームファイルの作成に失敗しました":               "Failed to create commentator game file",
+	"解説者向けゲームファイルの保存に失敗しました":               "Failed to save commentator game file",
+	"解説者向けゲームファイルの書き込みに失敗しました":             "Failed to write commentator game file",
+	"解説者向けゲームファイルを保存しました":                  "Saved commentator game file",
+	"記録から対象を特定できなかったため、インデックスが最小の候補を選択します": "Could not determine the target from the record, selecting the candidate with the smallest index",
+	"記録されたリクエストがありません":                     "No recorded requests",
+	"記録されたリクエストのパースに失敗しました":                "Failed to parse recorded request",
+	"設定ファイルのパースに失敗しました":                    "Failed to parse config file",
+	"設定ファイルの登録チームでインデックスの割り当てを上書きしました":     "Overwrote index assignment with registered team from config",
+	"設定ファイルの読み込みに失敗しました":                   "Failed to read config file",
+	"認証が無効のため、管理者向けのエンドポイントを無効にします":        "Admin endpoints are disabled because authentication is disabled",
+	"護衛": "Guard",
+	"護衛されたため、襲撃結果を設定しません":         "Attack was guarded, not setting attack result",
+	"護衛アクションを実行します":               "Running guard action",
+	"護衛フェーズを開始します":                "Starting guard phase",
+	"護衛対象が死亡しているため、護衛対象を設定しません":   "Guard target is dead, not setting guard target",
+	"護衛対象が自分自身であるため、護衛対象を設定しません":  "Guard target is self, not setting guard target",
+	"護衛対象が見つからなかったため、護衛対象を設定しません": "Guard target not found, not setting guard target",
+	"護衛対象を設定しました":                 "Set guard target",
+	"購読者の受信が遅れているため、購読を終了します":     "Dropping subscriber because it is falling behind",
+	"追放":           "Execution",
+	"追放フェーズを終了します": "Ending execution phase",
+	"追放フェーズを開始します": "Starting execution phase",
+	"追放対象がいないため、追放結果を設定しません": "No execution target, not setting execution result",
+	"追放結果を設定しました":            "Set execution result",
+	"配信中または配信済みのゲームが見つかりません": "No live or finished broadcast found for the game",
+	"重複したマッチを削除しました":         "Removed duplicate match",
+	"開始": "Start",
+	"閲覧者トークンを検証します":               "Verifying viewer token",
+	"除外対象の日であるため、フェーズをスキップします":    "Skipping phase because this day is excluded",
+	"霊能結果を設定しました":                 "Set medium result",
+	"音声ファイルのセグメント化が完了しました":        "Finished segmenting audio file",
+	"音声ファイルの事前変換が完了しました":          "Finished pre-converting audio file",
+	"音声ファイルの事前変換に失敗しました":          "Failed to pre-convert audio file",
+	"音声ファイルの分割が完了しました":            "Finished splitting audio file",
+	"音声ファイルの分割に失敗しました":            "Failed to split audio file",
+	"音声ファイルの変換が完了しました":            "Finished converting audio file",
+	"音声ファイルの変換に失敗しました":            "Failed to convert audio file",
+	"音声ファイルの長さの取得に失敗しました":         "Failed to get audio file duration",
+	"音声ファイルの長さを取得しました":            "Retrieved audio file duration",
+	"音声合成に失敗しました":                 "Speech synthesis failed",
+	"音声合成エンジンの作成に失敗しました":          "Failed to create speech synthesis engine",
+	"音声合成エンジンを作成しました":             "Created speech synthesis engine",
+	"音声合成キャッシュのコピーに失敗しました":        "Failed to copy speech synthesis cache entry",
+	"音声合成キャッシュの保存に失敗しました":         "Failed to save speech synthesis cache entry",
+	"音声合成キャッシュの初期化に失敗しました":        "Failed to initialize speech synthesis cache",
+	"音声合成キャッシュの削除に失敗しました":         "Failed to remove speech synthesis cache entry",
+	"音声合成キャッシュの読み込みに失敗したため、削除します": "Failed to load speech synthesis cache entry, removing it",
+	"音声合成キャッシュを使用しました":            "Used speech synthesis cache",
+	"音声合成キャッシュを初期化しました":           "Initialized speech synthesis cache",
+	"音声合成コマンドが指定されていません":          "No speech synthesis command specified",
+	"音声合成コマンドの実行に失敗しました":          "Failed to run speech synthesis command",
+	"音声合成サーバのURLの解析に失敗しました":       "Failed to parse speech synthesis server URL",
+}
@@ -0,0 +1,97 @@
+package locale
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+type Language string
+
+const (
+	L_JA Language = "ja"
+	L_EN Language = "en"
+)
+
+var catalogs = map[Language]map[string]string{
+	L_JA: nil,
+	L_EN: catalogEN,
+}
+
+var current atomic.Pointer[map[string]string]
+
+func SetLanguage(lang Language) error {
+	if lang == "" {
+		lang = L_JA
+	}
+	catalog, exists := catalogs[lang]
+	if !exists {
+		return errors.New("不明な言語です: " + string(lang))
+	}
+	if catalog == nil {
+		current.Store(nil)
+	} else {
+		current.Store(&catalog)
+	}
+	return nil
+}
+
+func T(message string) string {
+	catalog := current.Load()
+	if catalog == nil {
+		return message
+	}
+	return translate(*catalog, message)
+}
+
+func translate(catalog map[string]string, message string) string {
+	if translated, exists := catalog[message]; exists {
+		return translated
+	}
+	prefix, rest, found := strings.Cut(message, ": ")
+	if !found {
+		return message
+	}
+	if translated, exists := catalog[prefix]; exists {
+		return translated + ": " + translate(catalog, rest)
+	}
+	return message
+}
+
+type handler struct {
+	slog.Handler
+}
+
+func NewHandler(h slog.Handler) slog.Handler {
+	return &handler{Handler: h}
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, T(r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		record.AddAttrs(translateAttr(attr))
+		return true
+	})
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	translated := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		translated = append(translated, translateAttr(attr))
+	}
+	return &handler{Handler: h.Handler.WithAttrs(translated)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{Handler: h.Handler.WithGroup(name)}
+}
+
+func translateAttr(attr slog.Attr) slog.Attr {
+	if err, ok := attr.Value.Any().(error); ok {
+		return slog.String(attr.Key, T(err.Error()))
+	}
+	return attr
+}
@@ -1,7 +1,6 @@
 package logic
 
 import (
-	"fmt"
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -29,45 +28,20 @@ func (g *Game) doAttack() {
 			}
 		}
 		if attacked == nil && !g.setting.AttackVote.AllowNoTarget && len(candidates) > 0 {
-			rand := util.SelectRandomAgent(candidates)
+			rand := g.selectRandomAgent(model.R_ATTACK, candidates)
 			attacked = &rand
 		}
 
 		if attacked != nil && !g.isGuarded(attacked) {
 			g.getCurrentGameStatus().StatusMap[*attacked] = model.S_DEAD
 			g.getCurrentGameStatus().AttackedAgent = attacked
-			if g.gameLogger != nil {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,true", g.currentDay, attacked.Idx))
-			}
-			if g.realtimeBroadcaster != nil {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃"
-				packet.ToIdx = &attacked.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
+			g.publish(model.AttackEvent{Attacked: attacked})
 			slog.Info("襲撃結果を設定しました", "id", g.id, "agent", attacked.String())
 		} else if attacked != nil {
-			if g.gameLogger != nil {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,false", g.currentDay, attacked.Idx))
-			}
-			if g.realtimeBroadcaster != nil {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃"
-				idx := -1
-				packet.FromIdx = &idx
-				packet.ToIdx = &attacked.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
+			g.publish(model.AttackEvent{Attacked: attacked, Guarded: true})
 			slog.Info("護衛されたため、襲撃結果を設定しません", "id", g.id, "agent", attacked.String())
 		} else {
-			if g.gameLogger != nil {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,-1,true", g.currentDay))
-			}
-			if g.realtimeBroadcaster != nil {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃"
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
+			g.publish(model.AttackEvent{})
 			slog.Info("襲撃対象がいないため、襲撃結果を設定しません", "id", g.id)
 		}
 	}
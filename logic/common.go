@@ -3,6 +3,7 @@ package logic
 import (
 	"errors"
 	"log/slog"
+	"math/rand"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 	"github.com/aiwolfdial/aiwolf-nlp-server/util"
@@ -20,6 +21,10 @@ func (g *Game) findTargetByRequest(agent *model.Agent, request model.Request) (*
 	slog.Info("対象エージェントを受信しました", "id", g.id, "agent", agent.String(), "target", target.String())
 	return target, nil
 }
+func (g *Game) Close() {
+	g.closeAllAgents()
+}
+
 func (g *Game) closeAllAgents() {
 	for _, agent := range g.agents {
 		agent.Close()
@@ -120,13 +125,15 @@ func (g *Game) requestToAgent(agent *model.Agent, request model.Request) (string
 	default:
 		return "", errors.New("一致するリクエストがありません")
 	}
-	if g.jsonLogger != nil {
-		g.jsonLogger.TrackStartRequest(g.id, *agent, packet)
-	}
-	resp, err := agent.SendPacket(packet, g.config.Server.Timeout.Action, g.config.Server.Timeout.Response, g.config.Server.Timeout.Acceptable)
-	if g.jsonLogger != nil {
-		g.jsonLogger.TrackEndRequest(g.id, *agent, resp, err)
+	g.publish(model.RequestStartEvent{Agent: *agent, Packet: packet})
+	var resp string
+	var err error
+	if g.replayer != nil {
+		resp, err = g.replayer.Respond(agent, packet)
+	} else {
+		resp, err = agent.SendPacket(packet, g.config.Server.Timeout.Action, g.config.Server.Timeout.Response, g.config.Server.Timeout.Acceptable)
 	}
+	g.publish(model.RequestEndEvent{Agent: *agent, Response: resp, Err: err})
 	return resp, err
 }
 
@@ -143,6 +150,23 @@ func (g *Game) minimize(agent *model.Agent, talks []model.Talk, whispers []model
 	return talks[lastTalkIdx:], whispers[lastWhisperIdx:]
 }
 
+func (g *Game) shuffleAgents(request model.Request, agents []*model.Agent) {
+	if g.replayer != nil {
+		g.replayer.Order(request, agents)
+		return
+	}
+	rand.Shuffle(len(agents), func(i, j int) {
+		agents[i], agents[j] = agents[j], agents[i]
+	})
+}
+
+func (g *Game) selectRandomAgent(request model.Request, candidates []model.Agent) model.Agent {
+	if g.replayer != nil {
+		return g.replayer.Select(request, g.currentDay, candidates)
+	}
+	return util.SelectRandomAgent(candidates)
+}
+
 func (g *Game) getCurrentGameStatus() *model.GameStatus {
 	return g.gameStatuses[g.currentDay]
 }
@@ -163,48 +187,18 @@ func (g *Game) isAlive(agent *model.Agent) bool {
 	return g.getCurrentGameStatus().StatusMap[*agent] == model.S_ALIVE
 }
 
-func (g *Game) getRealtimeBroadcastPacket() model.BroadcastPacket {
-	g.realtimeBroadcasterPacketIdx++
-	packet := model.BroadcastPacket{
-		Id:        g.id,
-		Idx:       g.realtimeBroadcasterPacketIdx,
-		Day:       g.currentDay,
-		IsDay:     g.isDaytime,
-		Event:     "なし",
-		Message:   nil,
-		FromIdx:   nil,
-		ToIdx:     nil,
-		BubbleIdx: nil,
-	}
-	for _, a := range g.agents {
-		agent := struct {
-			Idx     int     `json:"idx"`
-			Team    string  `json:"team"`
-			Name    string  `json:"name"`
-			Profile *string `json:"profile,omitempty"`
-			Avatar  *string `json:"avatar,omitempty"`
-			Role    string  `json:"role"`
-			IsAlive bool    `json:"is_alive"`
-		}{
-			Idx:     a.Idx,
-			Team:    a.TeamName,
-			Name:    a.GameName,
-			Profile: a.ProfileDescription,
-			Role:    a.Role.Name,
-			IsAlive: g.isAlive(a),
-		}
-		if a.Profile != nil {
-			agent.Avatar = &a.Profile.AvatarURL
-		}
-		packet.Agents = append(packet.Agents, agent)
-	}
-	return packet
-}
-
 func (g *Game) GetRoleTeamNamesMap() map[model.Role][]string {
 	return util.GetRoleTeamNamesMap(g.agents)
 }
 
+func (g *Game) GetTeamNames() []string {
+	return util.GetTeamNames(g.agents)
+}
+
+func (g *Game) GetErrorTeamNames() []string {
+	return util.GetErrorTeamNames(g.agents)
+}
+
 func (g *Game) IsFinished() bool {
 	return g.isFinished
 }
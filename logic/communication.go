@@ -1,9 +1,7 @@
 package logic
 
 import (
-	"fmt"
 	"log/slog"
-	"math/rand"
 	"strings"
 	"unicode/utf8"
 
@@ -57,9 +55,7 @@ func (g *Game) conductCommunication(request model.Request) {
 	g.getCurrentGameStatus().RemainLengthMap = &remainLengthMap
 	g.getCurrentGameStatus().RemainSkipMap = &remainSkipMap
 
-	rand.Shuffle(len(agents), func(i, j int) {
-		agents[i], agents[j] = agents[j], agents[i]
-	})
+	g.shuffleAgents(request, agents)
 
 	idx := 0
 	for i := range talkSetting.MaxCount.PerDay {
@@ -208,31 +204,7 @@ func (g *Game) conductCommunication(request model.Request) {
 				remainCountMap[*agent] = 0
 				slog.Info("発言がオーバーであるため、残り発言回数を0にしました", "id", g.id, "agent", agent.String())
 			}
-			if g.gameLogger != nil {
-				if request == model.R_TALK {
-					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,talk,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
-				} else {
-					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,whisper,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
-				}
-			}
-			if g.realtimeBroadcaster != nil {
-				if request == model.R_TALK {
-					packet := g.getRealtimeBroadcastPacket()
-					packet.Event = "トーク"
-					packet.Message = &talk.Text
-					packet.BubbleIdx = &agent.Idx
-					g.realtimeBroadcaster.Broadcast(packet)
-				} else {
-					packet := g.getRealtimeBroadcastPacket()
-					packet.Event = "囁き"
-					packet.Message = &talk.Text
-					packet.BubbleIdx = &agent.Idx
-					g.realtimeBroadcaster.Broadcast(packet)
-				}
-			}
-			if g.ttsBroadcaster != nil {
-				g.ttsBroadcaster.BroadcastText(g.id, talk.Text, agent.Profile.VoiceID)
-			}
+			g.publish(model.TalkEvent{Talk: talk, Whisper: request == model.R_WHISPER})
 			slog.Info("発言を受信しました", "id", g.id, "agent", agent.String(), "text", text, "count", remainCountMap[*agent], "length", remainLengthMap[*agent], "skip", remainSkipMap[*agent])
 		}
 		if !cnt {
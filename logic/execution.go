@@ -1,7 +1,6 @@
 package logic
 
 import (
-	"fmt"
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -27,21 +26,13 @@ func (g *Game) doExecution() {
 		}
 	}
 	if executed == nil && len(candidates) > 0 {
-		rand := util.SelectRandomAgent(candidates)
+		rand := g.selectRandomAgent(model.R_VOTE, candidates)
 		executed = &rand
 	}
 	if executed != nil {
 		g.getCurrentGameStatus().StatusMap[*executed] = model.S_DEAD
 		g.getCurrentGameStatus().ExecutedAgent = executed
-		if g.gameLogger != nil {
-			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,execute,%d,%s", g.currentDay, executed.Idx, executed.Role.Name))
-		}
-		if g.realtimeBroadcaster != nil {
-			packet := g.getRealtimeBroadcastPacket()
-			packet.Event = "追放"
-			packet.ToIdx = &executed.Idx
-			g.realtimeBroadcaster.Broadcast(packet)
-		}
+		g.publish(model.ExecutionEvent{Executed: executed})
 		slog.Info("追放結果を設定しました", "id", g.id, "agent", executed.String())
 
 		g.getCurrentGameStatus().MediumResult = &model.Judge{
@@ -52,11 +43,7 @@ func (g *Game) doExecution() {
 		}
 		slog.Info("霊能結果を設定しました", "id", g.id, "target", executed.String(), "result", executed.Role.Species)
 	} else {
-		if g.realtimeBroadcaster != nil {
-			packet := g.getRealtimeBroadcastPacket()
-			packet.Event = "追放"
-			g.realtimeBroadcaster.Broadcast(packet)
-		}
+		g.publish(model.ExecutionEvent{})
 		slog.Warn("追放対象がいないため、追放結果を設定しません", "id", g.id)
 	}
 	slog.Info("追放フェーズを終了します", "id", g.id, "day", g.currentDay)
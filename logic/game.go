@@ -1,32 +1,28 @@
 package logic
 
 import (
-	"fmt"
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
-	"github.com/aiwolfdial/aiwolf-nlp-server/service"
 	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 	"github.com/oklog/ulid/v2"
 )
 
 type Game struct {
-	id                           string
-	agents                       []*model.Agent
-	winSide                      model.Team
-	isFinished                   bool
-	config                       *model.Config
-	setting                      *model.Setting
-	currentDay                   int
-	isDaytime                    bool
-	gameStatuses                 map[int]*model.GameStatus
-	lastTalkIdxMap               map[*model.Agent]int
-	lastWhisperIdxMap            map[*model.Agent]int
-	jsonLogger                   *service.JSONLogger
-	gameLogger                   *service.GameLogger
-	realtimeBroadcaster          *service.RealtimeBroadcaster
-	ttsBroadcaster               *service.TTSBroadcaster
-	realtimeBroadcasterPacketIdx int
+	id                string
+	agents            []*model.Agent
+	winSide           model.Team
+	isFinished        bool
+	config            *model.Config
+	setting           *model.Setting
+	currentDay        int
+	isDaytime         bool
+	gameStatuses      map[int]*model.GameStatus
+	lastTalkIdxMap    map[*model.Agent]int
+	lastWhisperIdxMap map[*model.Agent]int
+	observers         []model.GameObserver
+	summary           *model.GameSummary
+	replayer          Replayer
 }
 
 func NewGame(config *model.Config, settings *model.Setting, conns []model.Connection) *Game {
@@ -103,30 +99,31 @@ func NewGameWithRole(config *model.Config, settings *model.Setting, roleMapConns
 	}
 }
 
+func NewReplayGame(config *model.Config, settings *model.Setting, id string, agents []*model.Agent, replayer Replayer) *Game {
+	gameStatus := model.NewInitializeGameStatus(agents)
+	gameStatuses := make(map[int]*model.GameStatus)
+	gameStatuses[0] = &gameStatus
+	slog.Info("リプレイ用のゲームを作成しました", "id", id)
+	return &Game{
+		id:                id,
+		agents:            agents,
+		winSide:           model.T_NONE,
+		isFinished:        false,
+		config:            config,
+		setting:           settings,
+		currentDay:        0,
+		isDaytime:         true,
+		gameStatuses:      gameStatuses,
+		lastTalkIdxMap:    make(map[*model.Agent]int),
+		lastWhisperIdxMap: make(map[*model.Agent]int),
+		replayer:          replayer,
+	}
+}
+
 func (g *Game) Start() model.Team {
 	slog.Info("ゲームを開始します", "id", g.id)
-	if g.jsonLogger != nil {
-		g.jsonLogger.TrackStartGame(g.id, g.agents)
-	}
-	if g.gameLogger != nil {
-		g.gameLogger.TrackStartGame(g.id, g.agents)
-	}
-	if g.realtimeBroadcaster != nil {
-		g.realtimeBroadcaster.TrackStartGame(g.id, g.agents)
-	}
-	if g.ttsBroadcaster != nil {
-		g.ttsBroadcaster.CreateStream(g.id)
-	}
-	if g.realtimeBroadcaster != nil {
-		packet := g.getRealtimeBroadcastPacket()
-		packet.Event = "開始"
-		message := "ゲームが開始されました"
-		packet.Message = &message
-		g.realtimeBroadcaster.Broadcast(packet)
-	}
-	if g.ttsBroadcaster != nil {
-		g.ttsBroadcaster.BroadcastText(g.id, "ゲームが開始されました", 23)
-	}
+	g.summary = model.NewGameSummary(g.agents)
+	g.publish(model.GameStartEvent{})
 	g.requestToEveryone(model.R_INITIALIZE)
 	for {
 		g.progressDay()
@@ -144,33 +141,8 @@ func (g *Game) Start() model.Team {
 		}
 	}
 	g.requestToEveryone(model.R_FINISH)
-	if g.gameLogger != nil {
-		for _, agent := range g.agents {
-			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,status,%d,%s,%s,%s,%s", g.currentDay, agent.Idx, agent.Role.Name, g.getCurrentGameStatus().StatusMap[*agent].String(), agent.OriginalName, agent.GameName))
-		}
-		villagers, werewolves := util.CountAliveTeams(g.getCurrentGameStatus().StatusMap)
-		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,result,%d,%d,%s", g.currentDay, villagers, werewolves, g.winSide))
-	}
-	if g.realtimeBroadcaster != nil {
-		packet := g.getRealtimeBroadcastPacket()
-		packet.Event = "終了"
-		message := string(g.winSide)
-		packet.Message = &message
-		g.realtimeBroadcaster.Broadcast(packet)
-	}
-	if g.ttsBroadcaster != nil {
-		g.ttsBroadcaster.BroadcastText(g.id, "ゲームが終了しました", 23)
-	}
+	g.publish(model.GameEndEvent{WinSide: g.winSide, Summary: g.summary})
 	g.closeAllAgents()
-	if g.jsonLogger != nil {
-		g.jsonLogger.TrackEndGame(g.id, g.winSide)
-	}
-	if g.gameLogger != nil {
-		g.gameLogger.TrackEndGame(g.id)
-	}
-	if g.realtimeBroadcaster != nil {
-		g.realtimeBroadcaster.TrackEndGame(g.id)
-	}
 	slog.Info("ゲームが終了しました", "id", g.id, "winSide", g.winSide)
 	g.isFinished = true
 	return g.winSide
@@ -193,11 +165,7 @@ func (g *Game) progressDay() {
 	slog.Info("昼セクションを開始します", "id", g.id, "day", g.currentDay)
 	g.isDaytime = true
 	g.requestToEveryone(model.R_DAILY_INITIALIZE)
-	if g.gameLogger != nil {
-		for _, agent := range g.agents {
-			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,status,%d,%s,%s,%s,%s", g.currentDay, agent.Idx, agent.Role.Name, g.getCurrentGameStatus().StatusMap[*agent].String(), agent.OriginalName, agent.GameName))
-		}
-	}
+	g.publish(model.PhaseChangeEvent{IsDaytime: true})
 
 	for _, phase := range g.config.Logic.DayPhases {
 		if phase.OnlyDay != nil && *phase.OnlyDay != g.currentDay {
@@ -222,6 +190,7 @@ func (g *Game) progressNight() {
 	slog.Info("夜セクションを開始します", "id", g.id, "day", g.currentDay)
 	g.isDaytime = false
 	g.requestToEveryone(model.R_DAILY_FINISH)
+	g.publish(model.PhaseChangeEvent{IsDaytime: false})
 
 	for _, phase := range g.config.Logic.NightPhases {
 		if phase.OnlyDay != nil && *phase.OnlyDay != g.currentDay {
@@ -267,18 +236,22 @@ func (g *Game) GetID() string {
 	return g.id
 }
 
-func (g *Game) SetJSONLogger(logger *service.JSONLogger) {
-	g.jsonLogger = logger
-}
-
-func (g *Game) SetGameLogger(logger *service.GameLogger) {
-	g.gameLogger = logger
+func (g *Game) AddObserver(observer model.GameObserver) {
+	g.observers = append(g.observers, observer)
 }
 
-func (g *Game) SetRealtimeBroadcaster(broadcaster *service.RealtimeBroadcaster) {
-	g.realtimeBroadcaster = broadcaster
-}
-
-func (g *Game) SetTTSBroadcaster(broadcaster *service.TTSBroadcaster) {
-	g.ttsBroadcaster = broadcaster
+func (g *Game) publish(event model.GameEvent) {
+	state := model.GameState{
+		ID:        g.id,
+		Day:       g.currentDay,
+		IsDaytime: g.isDaytime,
+		Agents:    g.agents,
+		StatusMap: g.getCurrentGameStatus().StatusMap,
+	}
+	if g.summary != nil {
+		g.summary.Record(state, event)
+	}
+	for _, observer := range g.observers {
+		observer.OnGameEvent(state, event)
+	}
 }
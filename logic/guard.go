@@ -1,7 +1,6 @@
 package logic
 
 import (
-	"fmt"
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -37,15 +36,6 @@ func (g *Game) conductGuard(agent *model.Agent) {
 		Agent:  *agent,
 		Target: *target,
 	}
-	if g.gameLogger != nil {
-		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,guard,%d,%d,%s", g.currentDay, agent.Idx, target.Idx, target.Role.Name))
-	}
-	if g.realtimeBroadcaster != nil {
-		packet := g.getRealtimeBroadcastPacket()
-		packet.Event = "護衛"
-		packet.FromIdx = &agent.Idx
-		packet.ToIdx = &target.Idx
-		g.realtimeBroadcaster.Broadcast(packet)
-	}
+	g.publish(model.GuardEvent{Guard: *g.getCurrentGameStatus().Guard})
 	slog.Info("護衛対象を設定しました", "id", g.id, "target", target.String())
 }
@@ -0,0 +1,9 @@
+package logic
+
+import "github.com/aiwolfdial/aiwolf-nlp-server/model"
+
+type Replayer interface {
+	Respond(agent *model.Agent, packet model.Packet) (string, error)
+	Order(request model.Request, agents []*model.Agent)
+	Select(request model.Request, day int, candidates []model.Agent) model.Agent
+}
@@ -1,7 +1,6 @@
 package logic
 
 import (
-	"fmt"
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -37,34 +36,13 @@ func (g *Game) collectVotes(request model.Request, agents []*model.Agent) []mode
 				continue
 			}
 		}
-		votes = append(votes, model.Vote{
+		vote := model.Vote{
 			Day:    g.getCurrentGameStatus().Day,
 			Agent:  *agent,
 			Target: *target,
-		})
-		if g.gameLogger != nil {
-			if request == model.R_VOTE {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,vote,%d,%d", g.currentDay, agent.Idx, target.Idx))
-			} else {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attackVote,%d,%d", g.currentDay, agent.Idx, target.Idx))
-			}
-		}
-
-		if g.realtimeBroadcaster != nil {
-			if request == model.R_VOTE {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "投票"
-				packet.FromIdx = &agent.Idx
-				packet.ToIdx = &target.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			} else {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃投票"
-				packet.FromIdx = &agent.Idx
-				packet.ToIdx = &target.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
 		}
+		votes = append(votes, vote)
+		g.publish(model.VoteEvent{Vote: vote, Attack: request == model.R_ATTACK})
 		slog.Info("投票を受信しました", "id", g.id, "agent", agent.String(), "target", target.String())
 	}
 	return votes
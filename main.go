@@ -4,8 +4,10 @@ import (
 	"flag"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/locale"
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -35,9 +37,19 @@ func main() {
 	var (
 		configPath    = flag.String("c", "./default.yml", "設定ファイルのパス")
 		analyzerMode  = flag.Bool("a", false, "解析モード")
+		talkMode      = flag.Bool("t", false, "発話解析モード")
+		reportFormat  = flag.String("f", "", "解析結果のレポート形式 (csv, json, html)")
+		reportPath    = flag.String("o", "", "解析結果のレポートの出力先")
 		reductionMode = flag.Bool("r", false, "縮約モード")
 		srcConfigPath = flag.String("s", "", "ソース設定ファイルのパス")
 		dstConfigPath = flag.String("d", "", "デスティネーション設定ファイルのパス")
+		replayPath    = flag.String("p", "", "リプレイするJSONログのパス")
+		datasetPath   = flag.String("e", "", "データセットの出力先")
+		datasetTeams  = flag.String("team", "", "データセットに含めるチーム (カンマ区切り)")
+		datasetRoles  = flag.String("role", "", "データセットに含める役職 (カンマ区切り)")
+		datasetResult = flag.String("outcome", "", "データセットに含める勝敗 (win, lose, none のカンマ区切り)")
+		anonymize     = flag.Bool("anonymize", false, "データセットのチーム名を匿名化")
+		replayLogPath = flag.String("l", "", "リプレイ結果と比較するゲームログのパス")
 		showVersion   = flag.Bool("v", false, "バージョンを表示")
 		showHelp      = flag.Bool("h", false, "ヘルプを表示")
 	)
@@ -59,9 +71,52 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	if err := locale.SetLanguage(locale.Language(config.Server.Language)); err != nil {
+		panic(err)
+	}
+	slog.SetDefault(slog.New(locale.NewHandler(slog.NewTextHandler(os.Stderr, nil))))
 
 	if *analyzerMode {
-		core.Analyzer(*config)
+		report := core.Analyzer(*config)
+		if *reportFormat != "" {
+			if err := report.Export(*reportFormat, *reportPath); err != nil {
+				slog.Error("レポートの出力に失敗しました", "error", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if *talkMode {
+		report := core.TalkAnalyzer(*config)
+		if *reportFormat != "" {
+			if err := report.Export(*reportFormat, *reportPath); err != nil {
+				slog.Error("レポートの出力に失敗しました", "error", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if *datasetPath != "" {
+		options := core.DatasetOptions{
+			Teams:     splitFlag(*datasetTeams),
+			Roles:     splitFlag(*datasetRoles),
+			Outcomes:  splitFlag(*datasetResult),
+			Anonymize: *anonymize,
+		}
+		if err := core.ExportDataset(*config, *datasetPath, options); err != nil {
+			slog.Error("データセットの出力に失敗しました", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *replayPath != "" {
+		if err := core.Replay(*config, *replayPath, *replayLogPath); err != nil {
+			slog.Error("リプレイに失敗しました", "error", err)
+			os.Exit(1)
+		}
 		return
 	}
 
@@ -84,3 +139,16 @@ func main() {
 	}
 	server.Run()
 }
+
+func splitFlag(value string) []string {
+	if value == "" {
+		return nil
+	}
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -11,6 +11,11 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+var (
+	ErrResponseTimeout     = errors.New("リクエストのレスポンス受信がタイムアウトしました")
+	ErrNameResponseTimeout = errors.New("NAMEリクエストのレスポンス受信がタイムアウトしました")
+)
+
 type Agent struct {
 	Idx                int
 	TeamName           string
@@ -124,7 +129,7 @@ func (a *Agent) SendPacket(packet Packet, actionTimeout, responseTimeout, accept
 		case res := <-responseChan:
 			if strings.TrimRight(string(res), "\n") == a.OriginalName {
 				slog.Info("NAMEリクエストのレスポンスを受信しました", "agent", a.String(), "response", string(res))
-				return "", errors.New("リクエストのレスポンス受信がタイムアウトしました")
+				return "", ErrResponseTimeout
 			} else {
 				slog.Error("不正なNAMEリクエストのレスポンスを受信しました", "agent", a.String(), "response", string(res))
 				a.HasError = true
@@ -137,14 +142,16 @@ func (a *Agent) SendPacket(packet Packet, actionTimeout, responseTimeout, accept
 		case <-time.After(responseTimeout):
 			slog.Error("NAMEリクエストのレスポンス受信がタイムアウトしました", "agent", a.String())
 			a.HasError = true
-			return "", errors.New("NAMEリクエストのレスポンス受信がタイムアウトしました")
+			return "", ErrNameResponseTimeout
 		}
 	}
 	return "", nil
 }
 
 func (a Agent) Close() {
-	a.Connection.Close()
+	if a.Connection != nil {
+		a.Connection.Close()
+	}
 	slog.Info("エージェントをクローズしました", "agent", a.String())
 }
 
@@ -1,22 +1,129 @@
 package model
 
+import "github.com/aiwolfdial/aiwolf-nlp-server/locale"
+
+type BroadcastEvent string
+
+const (
+	BE_START       BroadcastEvent = "start"
+	BE_TALK        BroadcastEvent = "talk"
+	BE_WHISPER     BroadcastEvent = "whisper"
+	BE_VOTE        BroadcastEvent = "vote"
+	BE_ATTACK_VOTE BroadcastEvent = "attack_vote"
+	BE_EXECUTION   BroadcastEvent = "execution"
+	BE_ATTACK      BroadcastEvent = "attack"
+	BE_DIVINE      BroadcastEvent = "divine"
+	BE_GUARD       BroadcastEvent = "guard"
+	BE_END         BroadcastEvent = "end"
+)
+
+var broadcastEventLabels = map[BroadcastEvent]string{
+	BE_START:       "開始",
+	BE_TALK:        "トーク",
+	BE_WHISPER:     "囁き",
+	BE_VOTE:        "投票",
+	BE_ATTACK_VOTE: "襲撃投票",
+	BE_EXECUTION:   "追放",
+	BE_ATTACK:      "襲撃",
+	BE_DIVINE:      "占い",
+	BE_GUARD:       "護衛",
+	BE_END:         "終了",
+}
+
+func (e BroadcastEvent) Label() string {
+	if label, exists := broadcastEventLabels[e]; exists {
+		return locale.T(label)
+	}
+	return string(e)
+}
+
+type BroadcastAgent struct {
+	Idx     int     `json:"idx"`
+	Team    string  `json:"team"`
+	Name    string  `json:"name"`
+	Profile *string `json:"profile,omitempty"`
+	Avatar  *string `json:"avatar,omitempty"`
+	Role    string  `json:"role"`
+	IsAlive bool    `json:"is_alive"`
+}
+
+type BroadcastAgentDelta struct {
+	Idx     int     `json:"idx"`
+	Role    *string `json:"role,omitempty"`
+	IsAlive *bool   `json:"is_alive,omitempty"`
+}
+
 type BroadcastPacket struct {
-	Id     string `json:"id"`
-	Idx    int    `json:"idx"`
-	Day    int    `json:"day"`
-	IsDay  bool   `json:"is_day"`
-	Agents []struct {
-		Idx     int     `json:"idx"`
-		Team    string  `json:"team"`
-		Name    string  `json:"name"`
-		Profile *string `json:"profile,omitempty"`
-		Avatar  *string `json:"avatar,omitempty"`
-		Role    string  `json:"role"`
-		IsAlive bool    `json:"is_alive"`
-	} `json:"agents"`
-	Event     string  `json:"event"`
-	Message   *string `json:"message,omitempty"`
-	FromIdx   *int    `json:"from_idx,omitempty"`
-	ToIdx     *int    `json:"to_idx,omitempty"`
-	BubbleIdx *int    `json:"bubble_idx,omitempty"`
+	Id           string                `json:"id"`
+	Idx          int                   `json:"idx"`
+	Day          int                   `json:"day"`
+	IsDay        bool                  `json:"is_day"`
+	Snapshot     bool                  `json:"snapshot,omitempty"`
+	Agents       []BroadcastAgent      `json:"agents,omitempty"`
+	AgentChanges []BroadcastAgentDelta `json:"agent_changes,omitempty"`
+	Event        BroadcastEvent        `json:"event"`
+	Label        string                `json:"label"`
+	Message      *string               `json:"message,omitempty"`
+	FromIdx      *int                  `json:"from_idx,omitempty"`
+	ToIdx        *int                  `json:"to_idx,omitempty"`
+	BubbleIdx    *int                  `json:"bubble_idx,omitempty"`
+	Guarded      *bool                 `json:"guarded,omitempty"`
+	Species      *Species              `json:"species,omitempty"`
+	Result       *Team                 `json:"result,omitempty"`
+	Summary      *GameSummary          `json:"summary,omitempty"`
+}
+
+func (p BroadcastPacket) Diff(agents []BroadcastAgent) ([]BroadcastAgentDelta, bool) {
+	if len(agents) != len(p.Agents) {
+		return nil, false
+	}
+	deltas := []BroadcastAgentDelta{}
+	for i, agent := range p.Agents {
+		prev := agents[i]
+		if agent.Idx != prev.Idx || agent.Team != prev.Team || agent.Name != prev.Name ||
+			!equalStringPtr(agent.Profile, prev.Profile) || !equalStringPtr(agent.Avatar, prev.Avatar) {
+			return nil, false
+		}
+		delta := BroadcastAgentDelta{Idx: agent.Idx}
+		if agent.Role != prev.Role {
+			role := agent.Role
+			delta.Role = &role
+		}
+		if agent.IsAlive != prev.IsAlive {
+			isAlive := agent.IsAlive
+			delta.IsAlive = &isAlive
+		}
+		if delta.Role != nil || delta.IsAlive != nil {
+			deltas = append(deltas, delta)
+		}
+	}
+	return deltas, true
+}
+
+func (p BroadcastPacket) Apply(agents []BroadcastAgent) []BroadcastAgent {
+	if p.Snapshot {
+		return append([]BroadcastAgent(nil), p.Agents...)
+	}
+	agents = append([]BroadcastAgent(nil), agents...)
+	for _, delta := range p.AgentChanges {
+		for i := range agents {
+			if agents[i].Idx != delta.Idx {
+				continue
+			}
+			if delta.Role != nil {
+				agents[i].Role = *delta.Role
+			}
+			if delta.IsAlive != nil {
+				agents[i].IsAlive = *delta.IsAlive
+			}
+		}
+	}
+	return agents
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
@@ -18,6 +18,7 @@ type Config struct {
 	GameLogger          GameLoggerConfig          `yaml:"game_logger"`
 	RealtimeBroadcaster RealtimeBroadcasterConfig `yaml:"realtime_broadcaster"`
 	TTSBroadcaster      TTSBroadcasterConfig      `yaml:"tts_broadcaster"`
+	LogArchive          LogArchiveConfig          `yaml:"log_archive"`
 }
 
 type ServerConfig struct {
@@ -34,6 +35,17 @@ type ServerConfig struct {
 		Acceptable time.Duration `yaml:"acceptable"`
 	} `yaml:"timeout"`
 	MaxContinueErrorRatio float64 `yaml:"max_continue_error_ratio"`
+	Language              string  `yaml:"language"`
+	Concurrency           struct {
+		MaxGames        int `yaml:"max_games"`
+		MaxGamesPerTeam int `yaml:"max_games_per_team"`
+		MaxQueuedGames  int `yaml:"max_queued_games"`
+	} `yaml:"concurrency"`
+	Quota struct {
+		MaxConnectionsPerTeam int `yaml:"max_connections_per_team"`
+		MaxGamesPerHour       int `yaml:"max_games_per_hour"`
+		MaxGamesPerDay        int `yaml:"max_games_per_day"`
+	} `yaml:"quota"`
 }
 
 type GameConfig struct {
@@ -83,12 +95,23 @@ type Phase struct {
 }
 
 type MatchingConfig struct {
-	SelfMatch    bool   `yaml:"self_match"`
-	IsOptimize   bool   `yaml:"is_optimize"`
-	TeamCount    int    `yaml:"team_count"`
-	GameCount    int    `yaml:"game_count"`
-	OutputPath   string `yaml:"output_path"`
-	InfiniteLoop bool   `yaml:"infinite_loop"`
+	SelfMatch       bool          `yaml:"self_match"`
+	Policy          string        `yaml:"policy"`
+	MaxSeatsPerTeam int           `yaml:"max_seats_per_team"`
+	FallbackTimeout time.Duration `yaml:"fallback_timeout"`
+	HouseTeams      []string      `yaml:"house_teams"`
+	IsOptimize      bool          `yaml:"is_optimize"`
+	TeamCount       int           `yaml:"team_count"`
+	GameCount       int           `yaml:"game_count"`
+	OutputPath      string        `yaml:"output_path"`
+	InfiniteLoop    bool          `yaml:"infinite_loop"`
+	Teams           []string      `yaml:"teams"`
+	Retry           struct {
+		MaxCount         int           `yaml:"max_count"`
+		Backoff          time.Duration `yaml:"backoff"`
+		Substitute       bool          `yaml:"substitute"`
+		MaxSubstitutions int           `yaml:"max_substitutions"`
+	} `yaml:"retry"`
 }
 
 type CustomProfileConfig struct {
@@ -127,10 +150,13 @@ type GameLoggerConfig struct {
 }
 
 type RealtimeBroadcasterConfig struct {
-	Enable    bool          `yaml:"enable"`
-	Delay     time.Duration `yaml:"delay"`
-	OutputDir string        `yaml:"output_dir"`
-	Filename  string        `yaml:"filename"`
+	Enable           bool          `yaml:"enable"`
+	Delay            time.Duration `yaml:"delay"`
+	Blind            bool          `yaml:"blind"`
+	SnapshotInterval int           `yaml:"snapshot_interval"`
+	OutputDir        string        `yaml:"output_dir"`
+	CommentatorDir   string        `yaml:"commentator_dir"`
+	Filename         string        `yaml:"filename"`
 }
 
 type TTSBroadcasterConfig struct {
@@ -139,7 +165,9 @@ type TTSBroadcasterConfig struct {
 	TargetDuration time.Duration `yaml:"target_duration"`
 	SegmentDir     string        `yaml:"segment_dir"`
 	TempDir        string        `yaml:"temp_dir"`
+	Engine         string        `yaml:"engine"`
 	Host           string        `yaml:"host"`
+	Command        []string      `yaml:"command"`
 	Timeout        time.Duration `yaml:"timeout"`
 	FfmpegPath     string        `yaml:"ffmpeg_path"`
 	FfprobePath    string        `yaml:"ffprobe_path"`
@@ -147,6 +175,17 @@ type TTSBroadcasterConfig struct {
 	DurationArgs   []string      `yaml:"duration_args"`
 	PreConvertArgs []string      `yaml:"pre_convert_args"`
 	SplitArgs      []string      `yaml:"split_args"`
+	CacheDir       string        `yaml:"cache_dir"`
+	CacheMaxSizeMB int64         `yaml:"cache_max_size_mb"`
+}
+
+type LogArchiveConfig struct {
+	Enable        bool          `yaml:"enable"`
+	Interval      time.Duration `yaml:"interval"`
+	CompressAfter time.Duration `yaml:"compress_after"`
+	OutputDir     string        `yaml:"output_dir"`
+	MaxAge        time.Duration `yaml:"max_age"`
+	MaxSizeMB     int64         `yaml:"max_size_mb"`
 }
 
 func LoadFromPath(path string) (*Config, error) {
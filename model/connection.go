@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -14,6 +15,7 @@ type Connection struct {
 	OriginalName string
 	Conn         *websocket.Conn
 	Header       *http.Header
+	ConnectedAt  time.Time
 }
 
 func NewConnection(conn *websocket.Conn, header *http.Header) (*Connection, error) {
@@ -42,7 +44,17 @@ func NewConnection(conn *websocket.Conn, header *http.Header) (*Connection, erro
 		OriginalName: originalName,
 		Conn:         conn,
 		Header:       header,
+		ConnectedAt:  time.Now(),
 	}
 	slog.Info("クライアントが接続しました", "team_name", connection.TeamName, "original_name", connection.OriginalName, "remote_addr", conn.RemoteAddr().String())
 	return &connection, nil
 }
+
+func (c Connection) CloseWithReason(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	if err := c.Conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+		slog.Warn("クローズメッセージの送信に失敗しました", "error", err)
+	}
+	c.Conn.Close()
+	slog.Info("クライアントの接続を切断しました", "team_name", c.TeamName, "reason", reason)
+}
@@ -0,0 +1,94 @@
+package model
+
+type GameEventType string
+
+const (
+	GE_START         GameEventType = "start"
+	GE_REQUEST_START GameEventType = "request_start"
+	GE_REQUEST_END   GameEventType = "request_end"
+	GE_PHASE_CHANGE  GameEventType = "phase_change"
+	GE_TALK          GameEventType = "talk"
+	GE_VOTE          GameEventType = "vote"
+	GE_EXECUTION     GameEventType = "execution"
+	GE_ATTACK        GameEventType = "attack"
+	GE_DIVINE        GameEventType = "divine"
+	GE_GUARD         GameEventType = "guard"
+	GE_END           GameEventType = "end"
+)
+
+type GameState struct {
+	ID        string
+	Day       int
+	IsDaytime bool
+	Agents    []*Agent
+	StatusMap map[Agent]Status
+}
+
+type GameEvent interface {
+	Type() GameEventType
+}
+
+type GameObserver interface {
+	OnGameEvent(state GameState, event GameEvent)
+}
+
+type GameStartEvent struct{}
+
+type RequestStartEvent struct {
+	Agent  Agent
+	Packet Packet
+}
+
+type RequestEndEvent struct {
+	Agent    Agent
+	Response string
+	Err      error
+}
+
+type PhaseChangeEvent struct {
+	IsDaytime bool
+}
+
+type TalkEvent struct {
+	Talk    Talk
+	Whisper bool
+}
+
+type VoteEvent struct {
+	Vote   Vote
+	Attack bool
+}
+
+type ExecutionEvent struct {
+	Executed *Agent
+}
+
+type AttackEvent struct {
+	Attacked *Agent
+	Guarded  bool
+}
+
+type DivineEvent struct {
+	Judge Judge
+}
+
+type GuardEvent struct {
+	Guard Guard
+}
+
+type GameEndEvent struct {
+	WinSide Team
+	Summary *GameSummary
+}
+
+func (GameStartEvent) Type() GameEventType    { return GE_START }
+func (RequestStartEvent) Type() GameEventType { return GE_REQUEST_START }
+func (RequestEndEvent) Type() GameEventType   { return GE_REQUEST_END }
+func (PhaseChangeEvent) Type() GameEventType  { return GE_PHASE_CHANGE }
+func (TalkEvent) Type() GameEventType         { return GE_TALK }
+func (VoteEvent) Type() GameEventType         { return GE_VOTE }
+func (ExecutionEvent) Type() GameEventType    { return GE_EXECUTION }
+func (AttackEvent) Type() GameEventType       { return GE_ATTACK }
+func (DivineEvent) Type() GameEventType       { return GE_DIVINE }
+func (GuardEvent) Type() GameEventType        { return GE_GUARD }
+func (GameEndEvent) Type() GameEventType      { return GE_END }
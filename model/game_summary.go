@@ -0,0 +1,132 @@
+package model
+
+type GameSummary struct {
+	WinSide  Team            `json:"win_side"`
+	Days     int             `json:"days"`
+	Timeline []TimelineEntry `json:"timeline"`
+	Agents   []AgentSummary  `json:"agents"`
+}
+
+type TimelineEntry struct {
+	Day     int           `json:"day"`
+	Type    GameEventType `json:"type"`
+	Agent   *int          `json:"agent,omitempty"`
+	Target  *int          `json:"target,omitempty"`
+	Guarded bool          `json:"guarded,omitempty"`
+}
+
+type AgentSummary struct {
+	Idx           int            `json:"idx"`
+	Name          string         `json:"name"`
+	Team          string         `json:"team"`
+	Role          Role           `json:"role"`
+	Alive         bool           `json:"alive"`
+	SurvivalDays  int            `json:"survival_days"`
+	Talks         int            `json:"talks"`
+	Whispers      int            `json:"whispers"`
+	Votes         []VoteSummary  `json:"votes"`
+	DivineResults []JudgeSummary `json:"divine_results,omitempty"`
+	MediumResults []JudgeSummary `json:"medium_results,omitempty"`
+}
+
+type VoteSummary struct {
+	Day    int  `json:"day"`
+	Target int  `json:"target"`
+	Attack bool `json:"attack,omitempty"`
+}
+
+type JudgeSummary struct {
+	Day    int     `json:"day"`
+	Target int     `json:"target"`
+	Result Species `json:"result"`
+}
+
+func NewGameSummary(agents []*Agent) *GameSummary {
+	summary := &GameSummary{
+		WinSide:  T_NONE,
+		Timeline: []TimelineEntry{},
+		Agents:   make([]AgentSummary, 0, len(agents)),
+	}
+	for _, agent := range agents {
+		summary.Agents = append(summary.Agents, AgentSummary{
+			Idx:   agent.Idx,
+			Name:  agent.GameName,
+			Team:  agent.TeamName,
+			Role:  agent.Role,
+			Alive: true,
+			Votes: []VoteSummary{},
+		})
+	}
+	return summary
+}
+
+func (s *GameSummary) Record(state GameState, event GameEvent) {
+	switch e := event.(type) {
+	case TalkEvent:
+		if agent := s.agent(e.Talk.Agent.Idx); agent != nil && e.Talk.Text != T_SKIP && e.Talk.Text != T_FORCE_SKIP && e.Talk.Text != T_OVER {
+			if e.Whisper {
+				agent.Whispers++
+			} else {
+				agent.Talks++
+			}
+		}
+	case VoteEvent:
+		if agent := s.agent(e.Vote.Agent.Idx); agent != nil {
+			agent.Votes = append(agent.Votes, VoteSummary{Day: state.Day, Target: e.Vote.Target.Idx, Attack: e.Attack})
+		}
+	case ExecutionEvent:
+		if e.Executed == nil {
+			return
+		}
+		s.Timeline = append(s.Timeline, TimelineEntry{Day: state.Day, Type: GE_EXECUTION, Target: &e.Executed.Idx})
+		s.kill(e.Executed.Idx, state.Day)
+	case RequestStartEvent:
+		// 霊能結果は追放の翌日に伝えられるため、実際に霊能者に送られた情報から記録する
+		if e.Packet.Request == nil || *e.Packet.Request != R_DAILY_INITIALIZE || e.Packet.Info == nil || e.Packet.Info.MediumResult == nil {
+			return
+		}
+		if agent := s.agent(e.Agent.Idx); agent != nil {
+			result := e.Packet.Info.MediumResult
+			agent.MediumResults = append(agent.MediumResults, JudgeSummary{Day: result.Day, Target: result.Target.Idx, Result: result.Result})
+		}
+	case AttackEvent:
+		if e.Attacked == nil {
+			return
+		}
+		s.Timeline = append(s.Timeline, TimelineEntry{Day: state.Day, Type: GE_ATTACK, Target: &e.Attacked.Idx, Guarded: e.Guarded})
+		if !e.Guarded {
+			s.kill(e.Attacked.Idx, state.Day)
+		}
+	case GuardEvent:
+		agentIdx, targetIdx := e.Guard.Agent.Idx, e.Guard.Target.Idx
+		s.Timeline = append(s.Timeline, TimelineEntry{Day: state.Day, Type: GE_GUARD, Agent: &agentIdx, Target: &targetIdx})
+	case DivineEvent:
+		if agent := s.agent(e.Judge.Agent.Idx); agent != nil {
+			agent.DivineResults = append(agent.DivineResults, JudgeSummary{Day: state.Day, Target: e.Judge.Target.Idx, Result: e.Judge.Result})
+		}
+	case GameEndEvent:
+		s.WinSide = e.WinSide
+		s.Days = state.Day
+		for i := range s.Agents {
+			if s.Agents[i].Alive {
+				s.Agents[i].SurvivalDays = state.Day
+			}
+		}
+	}
+}
+
+func (s *GameSummary) agent(idx int) *AgentSummary {
+	for i := range s.Agents {
+		if s.Agents[i].Idx == idx {
+			return &s.Agents[i]
+		}
+	}
+	return nil
+}
+
+func (s *GameSummary) kill(idx int, day int) {
+	if agent := s.agent(idx); agent != nil && agent.Alive {
+		agent.Alive = false
+		agent.SurvivalDays = day
+	}
+}
@@ -2,11 +2,46 @@ package model
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type MatchWeight struct {
-	RoleIdxs map[Role][]int `json:"role_idxs"`
-	Weight   float64        `json:"weight"`
+	RoleIdxs   map[Role][]int `json:"role_idxs"`
+	Weight     float64        `json:"weight"`
+	Retries    int            `json:"retries"`
+	RetryAfter *time.Time     `json:"retry_after,omitempty"`
+	Failures   []MatchFailure `json:"failures,omitempty"`
+}
+
+type MatchFailure struct {
+	Reason        string              `json:"reason"`
+	ErrorTeams    []string            `json:"error_teams,omitempty"`
+	Substitutions []MatchSubstitution `json:"substitutions,omitempty"`
+	Timestamp     time.Time           `json:"timestamp"`
+}
+
+type MatchSubstitution struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+const (
+	MF_AGENT_ERROR = "agent_error"
+	MF_NO_WINNER   = "no_winner"
+)
+
+func (mw MatchWeight) SubstitutionCount() int {
+	count := 0
+	for _, failure := range mw.Failures {
+		if len(failure.Substitutions) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (mw MatchWeight) IsWaitingRetry(now time.Time) bool {
+	return mw.RetryAfter != nil && now.Before(*mw.RetryAfter)
 }
 
 func (mw MatchWeight) Equal(other MatchWeight) bool {
@@ -44,10 +79,16 @@ func (mw MatchWeight) MarshalJSON() ([]byte, error) {
 		roleIdxs[role.String()] = idxs
 	}
 	return json.Marshal(&struct {
-		RoleIdxs map[string][]int `json:"role_idxs"`
-		Weight   float64          `json:"weight"`
+		RoleIdxs   map[string][]int `json:"role_idxs"`
+		Weight     float64          `json:"weight"`
+		Retries    int              `json:"retries"`
+		RetryAfter *time.Time       `json:"retry_after,omitempty"`
+		Failures   []MatchFailure   `json:"failures,omitempty"`
 	}{
-		RoleIdxs: roleIdxs,
-		Weight:   mw.Weight,
+		RoleIdxs:   roleIdxs,
+		Weight:     mw.Weight,
+		Retries:    mw.Retries,
+		RetryAfter: mw.RetryAfter,
+		Failures:   mw.Failures,
 	})
 }
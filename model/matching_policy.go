@@ -0,0 +1,22 @@
+package model
+
+type MatchingPolicy string
+
+const (
+	MP_SELF          MatchingPolicy = "self"
+	MP_CROSS         MatchingPolicy = "cross"
+	MP_MIXED         MatchingPolicy = "mixed"
+	MP_SELF_FALLBACK MatchingPolicy = "self_fallback"
+	MP_HOUSE         MatchingPolicy = "house"
+)
+
+func MatchingPolicyFromConfig(config MatchingConfig) MatchingPolicy {
+	switch MatchingPolicy(config.Policy) {
+	case MP_SELF, MP_CROSS, MP_MIXED, MP_SELF_FALLBACK, MP_HOUSE:
+		return MatchingPolicy(config.Policy)
+	}
+	if config.SelfMatch {
+		return MP_SELF
+	}
+	return MP_CROSS
+}
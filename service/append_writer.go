@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const tempSuffix = ".tmp"
+
+type appendWriter struct {
+	path      string
+	atomic    bool
+	separator []byte
+	file      *os.File
+	writer    *bufio.Writer
+	count     int
+	timer     *time.Timer
+	closed    bool
+	mu        sync.Mutex
+}
+
+func newAppendWriter(path string, separator string, atomic bool) (*appendWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	target := path
+	if atomic {
+		target = path + tempSuffix
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &appendWriter{
+		path:      path,
+		atomic:    atomic,
+		separator: []byte(separator),
+		file:      file,
+		writer:    bufio.NewWriter(file),
+	}, nil
+}
+
+func (w *appendWriter) WriteRaw(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.writer.Write(data)
+	return err
+}
+
+func (w *appendWriter) WriteRecord(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.count > 0 {
+		if _, err := w.writer.Write(w.separator); err != nil {
+			return err
+		}
+	}
+	if _, err := w.writer.Write(data); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+func (w *appendWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Flush()
+}
+
+// 書き込みのたびにフラッシュせず、一定時間内の書き込みをまとめてフラッシュする
+func (w *appendWriter) FlushAfter(interval time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil || w.closed {
+		return
+	}
+	w.timer = time.AfterFunc(interval, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.timer = nil
+		if w.closed {
+			return
+		}
+		if err := w.writer.Flush(); err != nil {
+			slog.Error("ファイルのフラッシュに失敗しました", "error", err, "path", w.path)
+		}
+	})
+}
+
+func (w *appendWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if err := w.writer.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if w.atomic {
+		return os.Rename(w.path+tempSuffix, w.path)
+	}
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -1,15 +1,18 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 )
 
 type GameLogger struct {
@@ -21,8 +24,7 @@ type GameLog struct {
 	id       string
 	filename string
 	agents   []any
-	logs     []string
-	mu       sync.Mutex
+	writer   *appendWriter
 }
 
 func NewGameLogger(config model.Config) *GameLogger {
@@ -34,7 +36,6 @@ func NewGameLogger(config model.Config) *GameLogger {
 func (g *GameLogger) TrackStartGame(id string, agents []*model.Agent) {
 	data := &GameLog{
 		id:     id,
-		logs:   make([]string, 0),
 		agents: make([]any, 0),
 	}
 
@@ -61,57 +62,105 @@ func (g *GameLogger) TrackStartGame(id string, agents []*model.Agent) {
 	filename = strings.ReplaceAll(filename, "{teams}", strings.Join(teams, "_"))
 
 	data.filename = filename
+
+	filePath := filepath.Join(g.config.OutputDir, fmt.Sprintf("%s.log", filename))
+	writer, err := newAppendWriter(filePath, "\n", false)
+	if err != nil {
+		slog.Error("ゲームログファイルの作成に失敗しました", "error", err, "path", filePath)
+		return
+	}
+	if err := writer.WriteRecord([]byte(gamelog.Header())); err != nil {
+		slog.Error("ゲームログの書き込みに失敗しました", "error", err, "path", filePath)
+	}
+	data.writer = writer
 	g.data.Store(id, data)
 }
 
 func (g *GameLogger) TrackEndGame(id string) {
-	if _, exists := g.data.Load(id); exists {
-		g.saveLog(id)
-		g.data.Delete(id)
+	if dataInterface, exists := g.data.LoadAndDelete(id); exists {
+		data := dataInterface.(*GameLog)
+		if err := data.writer.Close(); err != nil {
+			slog.Error("ゲームログの保存に失敗しました", "error", err, "id", id)
+		}
 	}
 }
 
-func (g *GameLogger) AppendLog(id string, log string) {
+func (g *GameLogger) AppendEvent(id string, event gamelog.Event) {
 	if dataInterface, exists := g.data.Load(id); exists {
 		data := dataInterface.(*GameLog)
-
-		data.mu.Lock()
-		data.logs = append(data.logs, log)
-		logsCopy := make([]string, len(data.logs))
-		copy(logsCopy, data.logs)
-		data.mu.Unlock()
-
-		g.saveLogWithData(data.filename, logsCopy)
+		if err := data.writer.WriteRecord([]byte(gamelog.Format(event))); err != nil {
+			slog.Error("ゲームログの書き込みに失敗しました", "error", err, "id", id)
+		}
 	}
 }
 
-func (g *GameLogger) saveLog(id string) {
+func (g *GameLogger) Flush(id string) {
 	if dataInterface, exists := g.data.Load(id); exists {
 		data := dataInterface.(*GameLog)
-
-		data.mu.Lock()
-		logsCopy := make([]string, len(data.logs))
-		copy(logsCopy, data.logs)
-		filename := data.filename
-		data.mu.Unlock()
-
-		g.saveLogWithData(filename, logsCopy)
+		if err := data.writer.Flush(); err != nil {
+			slog.Error("ゲームログの書き込みに失敗しました", "error", err, "id", id)
+		}
 	}
 }
 
-func (g *GameLogger) saveLogWithData(filename string, logs []string) {
-	str := strings.Join(logs, "\n")
-
-	if _, err := os.Stat(g.config.OutputDir); os.IsNotExist(err) {
-		os.MkdirAll(g.config.OutputDir, 0755)
+func (g *GameLogger) WriteSummary(id string, summary model.GameSummary) {
+	if dataInterface, exists := g.data.Load(id); exists {
+		data := dataInterface.(*GameLog)
+		jsonData, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			slog.Error("ゲームサマリーのJSON化に失敗しました", "error", err, "id", id)
+			return
+		}
+		filePath := filepath.Join(g.config.OutputDir, fmt.Sprintf("%s.summary.json", data.filename))
+		if err := writeFileAtomic(filePath, jsonData); err != nil {
+			slog.Error("ゲームサマリーの保存に失敗しました", "error", err, "path", filePath)
+			return
+		}
+		slog.Info("ゲームサマリーを保存しました", "path", filePath)
 	}
+}
 
-	filePath := filepath.Join(g.config.OutputDir, fmt.Sprintf("%s.log", filename))
-	file, err := os.Create(filePath)
-	if err != nil {
-		return
+func (g *GameLogger) OnGameEvent(state model.GameState, event model.GameEvent) {
+	switch e := event.(type) {
+	case model.GameStartEvent:
+		g.TrackStartGame(state.ID, state.Agents)
+	case model.PhaseChangeEvent:
+		if e.IsDaytime {
+			g.appendStatuses(state)
+		}
+		// 記録ごとのフラッシュは避け、フェーズの切り替わりでまとめて書き出す
+		g.Flush(state.ID)
+	case model.TalkEvent:
+		g.AppendEvent(state.ID, gamelog.TalkEvent{Day: state.Day, Whisper: e.Whisper, Idx: e.Talk.Idx, Turn: e.Talk.Turn, AgentIdx: e.Talk.Agent.Idx, Text: e.Talk.Text})
+	case model.VoteEvent:
+		g.AppendEvent(state.ID, gamelog.VoteEvent{Day: state.Day, Attack: e.Attack, AgentIdx: e.Vote.Agent.Idx, TargetIdx: e.Vote.Target.Idx})
+	case model.ExecutionEvent:
+		if e.Executed != nil {
+			g.AppendEvent(state.ID, gamelog.ExecuteEvent{Day: state.Day, AgentIdx: e.Executed.Idx, Role: e.Executed.Role})
+		}
+	case model.AttackEvent:
+		if e.Attacked != nil {
+			g.AppendEvent(state.ID, gamelog.AttackEvent{Day: state.Day, TargetIdx: e.Attacked.Idx, Success: !e.Guarded})
+		} else {
+			g.AppendEvent(state.ID, gamelog.AttackEvent{Day: state.Day, TargetIdx: -1, Success: true})
+		}
+	case model.DivineEvent:
+		g.AppendEvent(state.ID, gamelog.DivineEvent{Day: state.Day, AgentIdx: e.Judge.Agent.Idx, TargetIdx: e.Judge.Target.Idx, Result: e.Judge.Result})
+	case model.GuardEvent:
+		g.AppendEvent(state.ID, gamelog.GuardEvent{Day: state.Day, AgentIdx: e.Guard.Agent.Idx, TargetIdx: e.Guard.Target.Idx, Role: e.Guard.Target.Role})
+	case model.GameEndEvent:
+		g.appendStatuses(state)
+		villagers, werewolves := util.CountAliveTeams(state.StatusMap)
+		g.AppendEvent(state.ID, gamelog.ResultEvent{Day: state.Day, Villagers: villagers, Werewolves: werewolves, WinSide: e.WinSide})
+		if e.Summary != nil {
+			g.WriteSummary(state.ID, *e.Summary)
+		}
+		g.TrackEndGame(state.ID)
 	}
-	defer file.Close()
+}
 
-	file.WriteString(str)
+func (g *GameLogger) appendStatuses(state model.GameState) {
+	for _, agent := range state.Agents {
+		g.AppendEvent(state.ID, gamelog.StatusEvent{Day: state.Day, Idx: agent.Idx, Role: agent.Role, Status: state.StatusMap[*agent], OriginalName: agent.OriginalName, GameName: agent.GameName})
+	}
 }
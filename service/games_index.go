@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+const gamesIndexFilename = "index.jsonl"
+
+type GameIndexEntry struct {
+	ID        string     `json:"id"`
+	Filename  string     `json:"filename"`
+	Teams     []string   `json:"teams"`
+	WinSide   model.Team `json:"win_side,omitempty"`
+	Day       int        `json:"day"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+type gamesIndex struct {
+	path    string
+	mu      sync.Mutex
+	entries []GameIndexEntry
+}
+
+func loadGamesIndex(dir string) (*gamesIndex, error) {
+	index := &gamesIndex{
+		path:    filepath.Join(dir, gamesIndexFilename),
+		entries: []GameIndexEntry{},
+	}
+	file, err := os.Open(index.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry GameIndexEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			slog.Warn("ゲームインデックスの行のパースに失敗しました", "path", index.path, "error", err)
+			continue
+		}
+		index.entries = append(index.entries, entry)
+	}
+	return index, scanner.Err()
+}
+
+func (index *gamesIndex) append(entry GameIndexEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	file, err := os.OpenFile(index.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	index.entries = append(index.entries, entry)
+	return nil
+}
+
+func (index *gamesIndex) list() []GameIndexEntry {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	return slices.Clone(index.entries)
+}
+
+func (rb *RealtimeBroadcaster) Games(page int, limit int) ([]GameIndexEntry, int) {
+	entries := rb.index.list()
+	rb.data.Range(func(_, value any) bool {
+		gameLog := value.(*RealtimeBroadcasterLog)
+		gameLog.mu.Lock()
+		entries = append(entries, GameIndexEntry{
+			ID:        gameLog.id,
+			Filename:  gameLog.filename,
+			Teams:     gameLog.teams,
+			StartedAt: gameLog.startedAt,
+		})
+		gameLog.mu.Unlock()
+		return true
+	})
+	slices.SortStableFunc(entries, func(a, b GameIndexEntry) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+
+	total := len(entries)
+	start := min(max(page-1, 0)*limit, total)
+	end := min(start+max(limit, 0), total)
+	return entries[start:end], total
+}
+
+func (gameLog *RealtimeBroadcasterLog) indexEntry(endedAt time.Time) GameIndexEntry {
+	gameLog.mu.Lock()
+	defer gameLog.mu.Unlock()
+	return GameIndexEntry{
+		ID:        gameLog.id,
+		Filename:  gameLog.filename,
+		Teams:     gameLog.teams,
+		WinSide:   gameLog.winSide,
+		Day:       gameLog.day,
+		StartedAt: gameLog.startedAt,
+		EndedAt:   &endedAt,
+	}
+}
+
+func uniqueTeams(teams []string) []string {
+	teams = slices.Clone(teams)
+	slices.Sort(teams)
+	return slices.Compact(teams)
+}
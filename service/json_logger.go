@@ -3,15 +3,13 @@ package service
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"slices"
-
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 )
 
@@ -25,10 +23,9 @@ type JSONLog struct {
 	filename     string
 	agents       []any
 	winSide      model.Team
-	entries      []any
+	writer       *appendWriter
 	timestampMap sync.Map
 	requestMap   sync.Map
-	mu           sync.Mutex
 }
 
 func NewJSONLogger(config model.Config) *JSONLogger {
@@ -41,17 +38,17 @@ func (j *JSONLogger) TrackStartGame(id string, agents []*model.Agent) {
 	data := &JSONLog{
 		id:      id,
 		agents:  make([]any, 0),
-		entries: make([]any, 0),
 		winSide: model.T_NONE,
 	}
 
 	for _, agent := range agents {
 		data.agents = append(data.agents,
 			map[string]any{
-				"idx":  agent.Idx,
-				"team": agent.TeamName,
-				"name": agent.OriginalName,
-				"role": agent.Role,
+				"idx":       agent.Idx,
+				"team":      agent.TeamName,
+				"name":      agent.OriginalName,
+				"game_name": agent.GameName,
+				"role":      agent.Role,
 			},
 		)
 	}
@@ -68,15 +65,38 @@ func (j *JSONLogger) TrackStartGame(id string, agents []*model.Agent) {
 	filename = strings.ReplaceAll(filename, "{teams}", strings.Join(teams, "_"))
 
 	data.filename = filename
+
+	agentsData, err := json.Marshal(data.agents)
+	if err != nil {
+		slog.Error("エージェント情報のJSON化に失敗しました", "error", err)
+		return
+	}
+	filePath := filepath.Join(j.config.OutputDir, fmt.Sprintf("%s.json", data.filename))
+	writer, err := newAppendWriter(filePath, ",", true)
+	if err != nil {
+		slog.Error("JSONログファイルの作成に失敗しました", "error", err, "path", filePath)
+		return
+	}
+	if err := writer.WriteRaw([]byte(`{"agents":` + string(agentsData) + `,"entries":[`)); err != nil {
+		slog.Error("JSONログの書き込みに失敗しました", "error", err, "path", filePath)
+	}
+	data.writer = writer
 	j.data.Store(id, data)
 }
 
 func (j *JSONLogger) TrackEndGame(id string, winSide model.Team) {
-	if dataInterface, exists := j.data.Load(id); exists {
+	if dataInterface, exists := j.data.LoadAndDelete(id); exists {
 		data := dataInterface.(*JSONLog)
 		data.winSide = winSide
-		j.saveGameData(id)
-		j.data.Delete(id)
+
+		idData, _ := json.Marshal(id)
+		winSideData, _ := json.Marshal(data.winSide)
+		if err := data.writer.WriteRaw([]byte(`],"game_id":` + string(idData) + `,"win_side":` + string(winSideData) + `}`)); err != nil {
+			slog.Error("JSONログの書き込みに失敗しました", "error", err, "id", id)
+		}
+		if err := data.writer.Close(); err != nil {
+			slog.Error("JSONログの保存に失敗しました", "error", err, "id", id)
+		}
 	}
 }
 
@@ -116,43 +136,26 @@ func (j *JSONLogger) TrackEndRequest(id string, agent model.Agent, response stri
 			entry["error"] = err.Error()
 		}
 
-		data.mu.Lock()
-		data.entries = append(data.entries, entry)
-		data.mu.Unlock()
-
-		j.saveGameData(id)
-	}
-}
-
-func (j *JSONLogger) saveGameData(id string) {
-	if dataInterface, exists := j.data.Load(id); exists {
-		data := dataInterface.(*JSONLog)
-
-		data.mu.Lock()
-		game := map[string]any{
-			"game_id":  id,
-			"win_side": data.winSide,
-			"agents":   data.agents,
-			"entries":  slices.Clone(data.entries),
-		}
-		data.mu.Unlock()
-
-		jsonData, err := json.Marshal(game)
+		jsonData, err := json.Marshal(entry)
 		if err != nil {
+			slog.Error("エントリのJSON化に失敗しました", "error", err)
 			return
 		}
-
-		if _, err := os.Stat(j.config.OutputDir); os.IsNotExist(err) {
-			os.MkdirAll(j.config.OutputDir, 0755)
-		}
-
-		filePath := filepath.Join(j.config.OutputDir, fmt.Sprintf("%s.json", data.filename))
-		file, err := os.Create(filePath)
-		if err != nil {
-			return
+		if err := data.writer.WriteRecord(jsonData); err != nil {
+			slog.Error("JSONログの書き込みに失敗しました", "error", err, "id", id)
 		}
-		defer file.Close()
+	}
+}
 
-		file.Write(jsonData)
+func (j *JSONLogger) OnGameEvent(state model.GameState, event model.GameEvent) {
+	switch e := event.(type) {
+	case model.GameStartEvent:
+		j.TrackStartGame(state.ID, state.Agents)
+	case model.RequestStartEvent:
+		j.TrackStartRequest(state.ID, e.Agent, e.Packet)
+	case model.RequestEndEvent:
+		j.TrackEndRequest(state.ID, e.Agent, e.Response, e.Err)
+	case model.GameEndEvent:
+		j.TrackEndGame(state.ID, e.WinSide)
 	}
 }
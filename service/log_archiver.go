@@ -0,0 +1,303 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+const archiveDateLayout = "2006-01-02"
+
+type LogArchiver struct {
+	config  model.LogArchiveConfig
+	sources []archiveSource
+}
+
+type archiveSource struct {
+	prefix string
+	dir    string
+	exts   []string
+}
+
+func NewLogArchiver(config model.Config) *LogArchiver {
+	la := &LogArchiver{
+		config:  config.LogArchive,
+		sources: []archiveSource{},
+	}
+	if la.config.Interval <= 0 {
+		la.config.Interval = time.Hour
+	}
+	if la.config.CompressAfter <= 0 {
+		la.config.CompressAfter = time.Hour
+	}
+	if config.GameLogger.Enable {
+		la.sources = append(la.sources, archiveSource{prefix: util.ArchivePrefixGame, dir: config.GameLogger.OutputDir, exts: []string{".log", ".summary.json"}})
+	}
+	if config.JSONLogger.Enable {
+		la.sources = append(la.sources, archiveSource{prefix: util.ArchivePrefixJSON, dir: config.JSONLogger.OutputDir, exts: []string{".json"}})
+	}
+	// リアルタイムブロードキャスターのログは終了後も /realtime で配信するため、アーカイブの対象外とする
+	return la
+}
+
+func (la *LogArchiver) Start() {
+	if err := os.MkdirAll(la.config.OutputDir, 0755); err != nil {
+		slog.Error("アーカイブディレクトリの作成に失敗しました", "error", err)
+		return
+	}
+	la.Sweep(time.Now())
+	ticker := time.NewTicker(la.config.Interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		la.Sweep(now)
+	}
+}
+
+func (la *LogArchiver) Sweep(now time.Time) {
+	for _, source := range la.sources {
+		la.compress(source, now)
+		la.bundle(source, now)
+	}
+	la.enforceRetention(now)
+}
+
+func (la *LogArchiver) compress(source archiveSource, now time.Time) {
+	for _, ext := range source.exts {
+		paths, err := filepath.Glob(filepath.Join(source.dir, "*"+ext))
+		if err != nil {
+			slog.Warn("ログファイルの取得に失敗しました", "dir", source.dir, "error", err)
+			continue
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || filepath.Base(path) == "games.json" || filepath.Base(path) == gamesIndexFilename {
+				continue
+			}
+			if now.Sub(info.ModTime()) < la.config.CompressAfter {
+				continue
+			}
+			if err := gzipFile(path, info.ModTime()); err != nil {
+				slog.Error("ログファイルの圧縮に失敗しました", "path", path, "error", err)
+				continue
+			}
+			slog.Info("ログファイルを圧縮しました", "path", path)
+		}
+	}
+}
+
+func (la *LogArchiver) bundle(source archiveSource, now time.Time) {
+	today := now.Format(archiveDateLayout)
+	days := make(map[string][]string)
+	for _, ext := range source.exts {
+		paths, err := filepath.Glob(filepath.Join(source.dir, "*"+ext+util.GzipExt))
+		if err != nil {
+			slog.Warn("圧縮済みログファイルの取得に失敗しました", "dir", source.dir, "error", err)
+			continue
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			day := info.ModTime().Format(archiveDateLayout)
+			if day >= today {
+				continue
+			}
+			days[day] = append(days[day], path)
+		}
+	}
+	for day, paths := range days {
+		archivePath := filepath.Join(la.config.OutputDir, source.prefix+"-"+day+".tar")
+		if err := appendArchive(archivePath, paths); err != nil {
+			slog.Error("アーカイブの作成に失敗しました", "path", archivePath, "error", err)
+			continue
+		}
+		for _, path := range paths {
+			os.Remove(path)
+		}
+		slog.Info("ログファイルをアーカイブしました", "path", archivePath, "count", len(paths))
+	}
+}
+
+func (la *LogArchiver) enforceRetention(now time.Time) {
+	type archive struct {
+		path string
+		date time.Time
+		size int64
+	}
+	paths, err := filepath.Glob(filepath.Join(la.config.OutputDir, "*.tar"))
+	if err != nil {
+		slog.Warn("アーカイブの取得に失敗しました", "error", err)
+		return
+	}
+	archives := []archive{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".tar")
+		date, err := time.ParseInLocation(archiveDateLayout, name[max(0, len(name)-len(archiveDateLayout)):], now.Location())
+		if err != nil {
+			date = info.ModTime()
+		}
+		archives = append(archives, archive{path: path, date: date, size: info.Size()})
+	}
+	slices.SortFunc(archives, func(a, b archive) int {
+		return a.date.Compare(b.date)
+	})
+
+	var total int64
+	for _, a := range archives {
+		total += a.size
+	}
+	maxSize := la.config.MaxSizeMB * 1024 * 1024
+	for _, a := range archives {
+		expired := la.config.MaxAge > 0 && now.Sub(a.date) > la.config.MaxAge
+		oversized := maxSize > 0 && total > maxSize
+		if !expired && !oversized {
+			continue
+		}
+		if err := os.Remove(a.path); err != nil {
+			slog.Error("アーカイブの削除に失敗しました", "path", a.path, "error", err)
+			continue
+		}
+		total -= a.size
+		slog.Info("保持期間または容量の上限を超えたアーカイブを削除しました", "path", a.path, "expired", expired, "oversized", oversized)
+	}
+}
+
+func gzipFile(path string, modTime time.Time) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dstPath := path + util.GzipExt
+	dst, err := os.CreateTemp(filepath.Dir(path), filepath.Base(dstPath)+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(dst)
+	writer.Name = filepath.Base(path)
+	writer.ModTime = modTime
+	if _, err := io.Copy(writer, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := os.Chmod(dst.Name(), 0644); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := os.Rename(dst.Name(), dstPath); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	os.Chtimes(dstPath, modTime, modTime)
+	return os.Remove(path)
+}
+
+func appendArchive(archivePath string, paths []string) error {
+	temp, err := os.CreateTemp(filepath.Dir(archivePath), filepath.Base(archivePath)+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	names := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		names[filepath.Base(path)] = struct{}{}
+	}
+
+	writer := tar.NewWriter(temp)
+	if existing, err := os.Open(archivePath); err == nil {
+		reader := tar.NewReader(existing)
+		for {
+			header, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				existing.Close()
+				return err
+			}
+			if _, exists := names[header.Name]; exists {
+				continue
+			}
+			if err := writer.WriteHeader(header); err != nil {
+				existing.Close()
+				return err
+			}
+			if _, err := io.Copy(writer, reader); err != nil {
+				existing.Close()
+				return err
+			}
+		}
+		existing.Close()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := addToArchive(writer, path); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), archivePath)
+}
+
+func addToArchive(writer *tar.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.Base(path)
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
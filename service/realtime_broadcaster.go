@@ -1,37 +1,144 @@
 package service
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/aiwolfdial/aiwolf-nlp-server/locale"
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+const (
+	RV_AUDIENCE    = "audience"
+	RV_COMMENTATOR = "commentator"
+)
+
+const (
+	subscriberBufferSize    = 256
+	defaultSnapshotInterval = 50
+	realtimeFlushInterval   = time.Second
+)
+
+var (
+	ErrRealtimeGameNotFound   = errors.New("配信中または配信済みのゲームが見つかりません")
+	ErrRealtimePacketNotFound = errors.New("指定されたインデックスのパケットが見つかりません")
 )
 
 type RealtimeBroadcaster struct {
 	config model.RealtimeBroadcasterConfig
 	data   sync.Map
+	ended  sync.Map
+	index  *gamesIndex
 }
 
 type RealtimeBroadcasterLog struct {
 	id        string
 	filename  string
 	agents    []any
-	logs      []string
-	logsMu    sync.Mutex
+	teams     []string
+	startedAt time.Time
+	day       int
+	winSide   model.Team
+	writer    *appendWriter
+	full      *appendWriter
+	mu        sync.Mutex
 	updatedAt time.Time
+	packetIdx int
+	streams   map[string]*realtimeStream
+	delayed   *delayQueue
+	done      chan struct{}
+}
+
+type realtimeStream struct {
+	packetIdx     int
+	packets       []RealtimePacket
+	subscribers   map[*RealtimeSubscription]struct{}
+	agents        []model.BroadcastAgent
+	sinceSnapshot int
+}
+
+type delayedPacket struct {
+	packet model.BroadcastPacket
+	due    time.Time
+}
+
+// ゲームの進行を止めないよう、上限を設けずに遅延配信するパケットを溜める
+type delayQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	packets []delayedPacket
+	closed  bool
+}
+
+func newDelayQueue() *delayQueue {
+	q := &delayQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *delayQueue) push(packet delayedPacket) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.packets = append(q.packets, packet)
+	q.cond.Signal()
+}
+
+func (q *delayQueue) pop() (delayedPacket, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.packets) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.packets) == 0 {
+		return delayedPacket{}, false
+	}
+	packet := q.packets[0]
+	q.packets = q.packets[1:]
+	return packet, true
+}
+
+func (q *delayQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+type RealtimePacket struct {
+	Idx  int
+	Data []byte
+}
+
+type RealtimeSubscription struct {
+	Backlog []RealtimePacket
+	Packets <-chan RealtimePacket
+	packets chan RealtimePacket
+	gameLog *RealtimeBroadcasterLog
+	stream  *realtimeStream
+	once    sync.Once
 }
 
 func NewRealtimeBroadcaster(config model.Config) *RealtimeBroadcaster {
 	rb := &RealtimeBroadcaster{
 		config: config.RealtimeBroadcaster,
 	}
+	if rb.config.SnapshotInterval <= 0 {
+		rb.config.SnapshotInterval = defaultSnapshotInterval
+	}
+	if rb.config.CommentatorDir == "" {
+		rb.config.CommentatorDir = filepath.Join(filepath.Dir(rb.config.OutputDir), "commentator")
+	}
 	if err := os.MkdirAll(rb.config.OutputDir, 0755); err != nil {
 		slog.Error("出力ディレクトリの作成に失敗しました", "error", err)
 		return nil
@@ -40,7 +147,16 @@ func NewRealtimeBroadcaster(config model.Config) *RealtimeBroadcaster {
 		slog.Error("ゲーム一覧ファイルの初期化に失敗しました", "error", err)
 		return nil
 	}
-	slog.Info("リアルタイムブロードキャスターを初期化しました", "output_dir", rb.config.OutputDir)
+	index, err := loadGamesIndex(rb.config.OutputDir)
+	if err != nil {
+		slog.Error("ゲームインデックスの読み込みに失敗しました", "error", err)
+		return nil
+	}
+	rb.index = index
+	for _, entry := range index.list() {
+		rb.ended.Store(entry.ID, entry.Filename)
+	}
+	slog.Info("リアルタイムブロードキャスターを初期化しました", "output_dir", rb.config.OutputDir, "delay", rb.config.Delay, "blind", rb.config.Blind)
 	return rb
 }
 
@@ -64,53 +180,505 @@ func (rb *RealtimeBroadcaster) TrackStartGame(id string, agents []*model.Agent)
 	filename = strings.ReplaceAll(filename, "{timestamp}", fmt.Sprintf("%d", time.Now().Unix()))
 	filename = strings.ReplaceAll(filename, "{teams}", strings.Join(teamNames, "_"))
 
+	filePath := filepath.Join(rb.config.OutputDir, fmt.Sprintf("%s.jsonl", filename))
+	writer, err := newAppendWriter(filePath, "\n", false)
+	if err != nil {
+		slog.Error("ゲームファイルの作成に失敗しました", "error", err, "path", filePath)
+		return
+	}
+
+	// 観客向けには隠す情報を含むため、公開されない別のディレクトリに保存する
+	var full *appendWriter
+	if rb.config.Blind {
+		fullPath := filepath.Join(rb.config.CommentatorDir, fmt.Sprintf("%s.jsonl", filename))
+		full, err = newAppendWriter(fullPath, "\n", false)
+		if err != nil {
+			writer.Close()
+			slog.Error("解説者向けゲームファイルの作成に失敗しました", "error", err, "path", fullPath)
+			return
+		}
+	}
+
 	gameLog := &RealtimeBroadcasterLog{
 		id:        id,
 		filename:  filename,
 		agents:    agentData,
-		logs:      make([]string, 0),
+		teams:     uniqueTeams(teamNames),
+		startedAt: time.Now(),
+		writer:    writer,
+		full:      full,
 		updatedAt: time.Now(),
+		streams: map[string]*realtimeStream{
+			RV_AUDIENCE:    newRealtimeStream(),
+			RV_COMMENTATOR: newRealtimeStream(),
+		},
+	}
+	if rb.config.Delay > 0 {
+		gameLog.delayed = newDelayQueue()
+		gameLog.done = make(chan struct{})
+		go rb.runDelayQueue(gameLog)
 	}
 
 	rb.data.Store(id, gameLog)
 }
 
+func newRealtimeStream() *realtimeStream {
+	return &realtimeStream{
+		packets:     []RealtimePacket{},
+		subscribers: make(map[*RealtimeSubscription]struct{}),
+	}
+}
+
 func (rb *RealtimeBroadcaster) TrackEndGame(id string) {
 	if gameLogInterface, exists := rb.data.Load(id); exists {
 		gameLog := gameLogInterface.(*RealtimeBroadcasterLog)
-		gameLog.logsMu.Lock()
-		logs := make([]string, len(gameLog.logs))
-		copy(logs, gameLog.logs)
-		filename := gameLog.filename
-		gameLog.logsMu.Unlock()
+		if gameLog.delayed == nil {
+			rb.closeGameLog(gameLog)
+			return
+		}
+		gameLog.delayed.close()
+		go func() {
+			<-gameLog.done
+			rb.closeGameLog(gameLog)
+		}()
+	}
+}
 
-		rb.writeGameFile(filename, logs)
-		rb.writeGamesListFile()
-		rb.data.Delete(id)
+func (rb *RealtimeBroadcaster) closeGameLog(gameLog *RealtimeBroadcasterLog) {
+	if err := gameLog.writer.Close(); err != nil {
+		slog.Error("ゲームファイルの保存に失敗しました", "error", err, "path", gameLog.writer.path)
+	} else {
+		slog.Info("ゲームファイルを保存しました", "path", gameLog.writer.path)
+	}
+	if gameLog.full != nil {
+		if err := gameLog.full.Close(); err != nil {
+			slog.Error("解説者向けゲームファイルの保存に失敗しました", "error", err, "path", gameLog.full.path)
+		} else {
+			slog.Info("解説者向けゲームファイルを保存しました", "path", gameLog.full.path)
+		}
+	}
+	if err := rb.index.append(gameLog.indexEntry(time.Now())); err != nil {
+		slog.Error("ゲームインデックスの更新に失敗しました", "error", err, "path", rb.index.path)
+	}
+	rb.ended.Store(gameLog.id, gameLog.filename)
+	rb.data.Delete(gameLog.id)
+
+	gameLog.mu.Lock()
+	for _, stream := range gameLog.streams {
+		for subscription := range stream.subscribers {
+			close(subscription.packets)
+		}
+		stream.subscribers = nil
+		stream.packets = nil
+	}
+	gameLog.mu.Unlock()
+	rb.writeGamesListFile()
+}
+
+func (rb *RealtimeBroadcaster) runDelayQueue(gameLog *RealtimeBroadcasterLog) {
+	defer close(gameLog.done)
+	for {
+		delayed, ok := gameLog.delayed.pop()
+		if !ok {
+			return
+		}
+		time.Sleep(time.Until(delayed.due))
+		rb.publishAudience(gameLog, delayed.packet)
 	}
 }
 
 func (rb *RealtimeBroadcaster) Broadcast(packet model.BroadcastPacket) {
-	data, err := json.Marshal(packet)
+	gameLogInterface, exists := rb.data.Load(packet.Id)
+	if !exists {
+		return
+	}
+	gameLog := gameLogInterface.(*RealtimeBroadcasterLog)
+
+	gameLog.mu.Lock()
+	stream := gameLog.streams[RV_COMMENTATOR]
+	data, err := json.Marshal(stream.encode(packet, rb.config.SnapshotInterval))
+	if err != nil {
+		gameLog.mu.Unlock()
+		slog.Error("パケットのJSON化に失敗しました", "error", err)
+		return
+	}
+	if gameLog.full != nil {
+		if err := gameLog.full.WriteRecord(data); err != nil {
+			slog.Error("解説者向けゲームファイルの書き込みに失敗しました", "error", err, "path", gameLog.full.path)
+		}
+		gameLog.full.FlushAfter(realtimeFlushInterval)
+	}
+	stream.publish(RealtimePacket{Idx: packet.Idx, Data: data}, packet.Id)
+	gameLog.mu.Unlock()
+
+	if rb.config.Blind {
+		redacted, ok := redactPacket(packet)
+		if !ok {
+			return
+		}
+		packet = redacted
+	}
+	if gameLog.delayed != nil {
+		gameLog.delayed.push(delayedPacket{packet: packet, due: time.Now().Add(rb.config.Delay)})
+		return
+	}
+	rb.publishAudience(gameLog, packet)
+}
+
+func (rb *RealtimeBroadcaster) publishAudience(gameLog *RealtimeBroadcasterLog, packet model.BroadcastPacket) {
+	gameLog.mu.Lock()
+	stream := gameLog.streams[RV_AUDIENCE]
+	stream.packetIdx++
+	packet.Idx = stream.packetIdx
+	data, err := json.Marshal(stream.encode(packet, rb.config.SnapshotInterval))
 	if err != nil {
+		gameLog.mu.Unlock()
 		slog.Error("パケットのJSON化に失敗しました", "error", err)
 		return
 	}
+	if err := gameLog.writer.WriteRecord(data); err != nil {
+		gameLog.mu.Unlock()
+		slog.Error("ゲームファイルの書き込みに失敗しました", "error", err, "path", gameLog.writer.path)
+		return
+	}
+	gameLog.writer.FlushAfter(realtimeFlushInterval)
+	gameLog.updatedAt = time.Now()
+	stream.publish(RealtimePacket{Idx: packet.Idx, Data: data}, packet.Id)
+	gameLog.mu.Unlock()
+
+	rb.writeGamesListFile()
+	slog.Info("JSONLファイルにブロードキャストを保存しました", "game_id", packet.Id)
+}
+
+func (stream *realtimeStream) encode(packet model.BroadcastPacket, interval int) model.BroadcastPacket {
+	if stream.agents != nil && stream.sinceSnapshot < interval && packet.Event != model.BE_END {
+		if deltas, ok := packet.Diff(stream.agents); ok {
+			stream.agents = packet.Agents
+			stream.sinceSnapshot++
+			packet.Agents = nil
+			packet.AgentChanges = deltas
+			return packet
+		}
+	}
+	stream.agents = packet.Agents
+	stream.sinceSnapshot = 0
+	packet.Snapshot = true
+	return packet
+}
+
+func (stream *realtimeStream) publish(packet RealtimePacket, id string) {
+	if stream.subscribers == nil {
+		return
+	}
+	stream.packets = append(stream.packets, packet)
+	for subscription := range stream.subscribers {
+		select {
+		case subscription.packets <- packet:
+		default:
+			slog.Warn("購読者の受信が遅れているため、購読を終了します", "game_id", id)
+			delete(stream.subscribers, subscription)
+			close(subscription.packets)
+		}
+	}
+}
+
+func redactPacket(packet model.BroadcastPacket) (model.BroadcastPacket, bool) {
+	switch packet.Event {
+	case model.BE_END:
+		return packet, true
+	case model.BE_WHISPER, model.BE_DIVINE, model.BE_GUARD, model.BE_ATTACK_VOTE:
+		return packet, false
+	case model.BE_ATTACK:
+		if packet.Guarded != nil && *packet.Guarded {
+			packet.ToIdx = nil
+		}
+		packet.Guarded = nil
+	}
+	packet.Agents = slices.Clone(packet.Agents)
+	for i := range packet.Agents {
+		packet.Agents[i].Role = ""
+	}
+	return packet, true
+}
+
+func (rb *RealtimeBroadcaster) OnGameEvent(state model.GameState, event model.GameEvent) {
+	if _, ok := event.(model.GameStartEvent); ok {
+		rb.TrackStartGame(state.ID, state.Agents)
+	}
+	gameLogInterface, exists := rb.data.Load(state.ID)
+	if !exists {
+		return
+	}
+	gameLog := gameLogInterface.(*RealtimeBroadcasterLog)
+
+	switch e := event.(type) {
+	case model.GameStartEvent:
+		packet := gameLog.newPacket(state, model.BE_START)
+		message := locale.T("ゲームが開始されました")
+		packet.Message = &message
+		rb.Broadcast(packet)
+	case model.TalkEvent:
+		event := model.BE_TALK
+		if e.Whisper {
+			event = model.BE_WHISPER
+		}
+		packet := gameLog.newPacket(state, event)
+		message := e.Talk.Text
+		idx := e.Talk.Agent.Idx
+		packet.Message = &message
+		packet.BubbleIdx = &idx
+		rb.Broadcast(packet)
+	case model.VoteEvent:
+		event := model.BE_VOTE
+		if e.Attack {
+			event = model.BE_ATTACK_VOTE
+		}
+		packet := gameLog.newPacket(state, event)
+		fromIdx, toIdx := e.Vote.Agent.Idx, e.Vote.Target.Idx
+		packet.FromIdx = &fromIdx
+		packet.ToIdx = &toIdx
+		rb.Broadcast(packet)
+	case model.ExecutionEvent:
+		packet := gameLog.newPacket(state, model.BE_EXECUTION)
+		if e.Executed != nil {
+			packet.ToIdx = &e.Executed.Idx
+		}
+		rb.Broadcast(packet)
+	case model.AttackEvent:
+		packet := gameLog.newPacket(state, model.BE_ATTACK)
+		if e.Attacked != nil {
+			guarded := e.Guarded
+			packet.ToIdx = &e.Attacked.Idx
+			packet.Guarded = &guarded
+		}
+		rb.Broadcast(packet)
+	case model.DivineEvent:
+		packet := gameLog.newPacket(state, model.BE_DIVINE)
+		fromIdx, toIdx := e.Judge.Agent.Idx, e.Judge.Target.Idx
+		species := e.Judge.Result
+		packet.FromIdx = &fromIdx
+		packet.ToIdx = &toIdx
+		packet.Species = &species
+		rb.Broadcast(packet)
+	case model.GuardEvent:
+		packet := gameLog.newPacket(state, model.BE_GUARD)
+		fromIdx, toIdx := e.Guard.Agent.Idx, e.Guard.Target.Idx
+		packet.FromIdx = &fromIdx
+		packet.ToIdx = &toIdx
+		rb.Broadcast(packet)
+	case model.GameEndEvent:
+		gameLog.mu.Lock()
+		gameLog.winSide = e.WinSide
+		gameLog.mu.Unlock()
+		packet := gameLog.newPacket(state, model.BE_END)
+		result := e.WinSide
+		packet.Result = &result
+		packet.Summary = e.Summary
+		rb.Broadcast(packet)
+		rb.TrackEndGame(state.ID)
+	}
+}
+
+func (gameLog *RealtimeBroadcasterLog) newPacket(state model.GameState, event model.BroadcastEvent) model.BroadcastPacket {
+	gameLog.mu.Lock()
+	gameLog.packetIdx++
+	idx := gameLog.packetIdx
+	gameLog.day = state.Day
+	gameLog.mu.Unlock()
+
+	packet := model.BroadcastPacket{
+		Id:        state.ID,
+		Idx:       idx,
+		Day:       state.Day,
+		IsDay:     state.IsDaytime,
+		Event:     event,
+		Label:     event.Label(),
+		Message:   nil,
+		FromIdx:   nil,
+		ToIdx:     nil,
+		BubbleIdx: nil,
+	}
+	for _, a := range state.Agents {
+		agent := model.BroadcastAgent{
+			Idx:     a.Idx,
+			Team:    a.TeamName,
+			Name:    a.GameName,
+			Profile: a.ProfileDescription,
+			Role:    a.Role.Name,
+			IsAlive: state.StatusMap[*a] == model.S_ALIVE,
+		}
+		if a.Profile != nil {
+			agent.Avatar = &a.Profile.AvatarURL
+		}
+		packet.Agents = append(packet.Agents, agent)
+	}
+	return packet
+}
+
+func (rb *RealtimeBroadcaster) Subscribe(id string, view string, from int) (*RealtimeSubscription, error) {
+	if gameLogInterface, exists := rb.data.Load(id); exists {
+		gameLog := gameLogInterface.(*RealtimeBroadcasterLog)
+		gameLog.mu.Lock()
+		defer gameLog.mu.Unlock()
+
+		stream, exists := gameLog.streams[view]
+		if !exists {
+			return nil, ErrRealtimeGameNotFound
+		}
+		backlog, err := resumeBacklog(stream.packets, from)
+		if err != nil {
+			return nil, err
+		}
+		packets := make(chan RealtimePacket, subscriberBufferSize)
+		subscription := &RealtimeSubscription{
+			Backlog: backlog,
+			Packets: packets,
+			packets: packets,
+		}
+		if stream.subscribers == nil {
+			close(packets)
+			return subscription, nil
+		}
+		subscription.gameLog = gameLog
+		subscription.stream = stream
+		stream.subscribers[subscription] = struct{}{}
+		slog.Info("ブロードキャストの購読を開始しました", "game_id", id, "view", view, "from", from)
+		return subscription, nil
+	}
+
+	if filenameInterface, exists := rb.ended.Load(id); exists {
+		all, err := rb.readEndedPackets(filenameInterface.(string), view)
+		if err != nil {
+			return nil, err
+		}
+		backlog, err := resumeBacklog(all, from)
+		if err != nil {
+			return nil, err
+		}
+		packets := make(chan RealtimePacket)
+		close(packets)
+		return &RealtimeSubscription{
+			Backlog: backlog,
+			Packets: packets,
+			packets: packets,
+		}, nil
+	}
+	return nil, ErrRealtimeGameNotFound
+}
+
+func (s *RealtimeSubscription) Close() {
+	s.once.Do(func() {
+		if s.gameLog == nil {
+			return
+		}
+		s.gameLog.mu.Lock()
+		defer s.gameLog.mu.Unlock()
+		if _, exists := s.stream.subscribers[s]; exists {
+			delete(s.stream.subscribers, s)
+			close(s.packets)
+		}
+	})
+}
 
-	if gameLogInterface, exists := rb.data.Load(packet.Id); exists {
+func (rb *RealtimeBroadcaster) Reconstruct(id string, view string, idx int) (model.BroadcastPacket, error) {
+	if gameLogInterface, exists := rb.data.Load(id); exists {
 		gameLog := gameLogInterface.(*RealtimeBroadcasterLog)
-		gameLog.logsMu.Lock()
-		gameLog.logs = append(gameLog.logs, string(data))
-		gameLog.updatedAt = time.Now()
-		logs := make([]string, len(gameLog.logs))
-		copy(logs, gameLog.logs)
-		filename := gameLog.filename
-		gameLog.logsMu.Unlock()
+		gameLog.mu.Lock()
+		stream, exists := gameLog.streams[view]
+		var packets []RealtimePacket
+		if exists {
+			packets = slices.Clone(stream.packets)
+		}
+		gameLog.mu.Unlock()
+		if !exists {
+			return model.BroadcastPacket{}, ErrRealtimeGameNotFound
+		}
+		return ReconstructBroadcastPacket(packets, idx)
+	}
+
+	if filenameInterface, exists := rb.ended.Load(id); exists {
+		packets, err := rb.readEndedPackets(filenameInterface.(string), view)
+		if err != nil {
+			return model.BroadcastPacket{}, err
+		}
+		return ReconstructBroadcastPacket(packets, idx)
+	}
+	return model.BroadcastPacket{}, ErrRealtimeGameNotFound
+}
+
+func ReconstructBroadcastPacket(packets []RealtimePacket, idx int) (model.BroadcastPacket, error) {
+	var agents []model.BroadcastAgent
+	for i, data := range packets {
+		var packet model.BroadcastPacket
+		if err := json.Unmarshal(data.Data, &packet); err != nil {
+			return model.BroadcastPacket{}, err
+		}
+		agents = packet.Apply(agents)
+		if packet.Idx == idx || (idx <= 0 && i == len(packets)-1) {
+			packet.Snapshot = true
+			packet.Agents = agents
+			packet.AgentChanges = nil
+			return packet, nil
+		}
+	}
+	return model.BroadcastPacket{}, ErrRealtimePacketNotFound
+}
+
+func resumeBacklog(packets []RealtimePacket, from int) ([]RealtimePacket, error) {
+	backlog := []RealtimePacket{}
+	for _, packet := range packets {
+		if packet.Idx > from {
+			backlog = append(backlog, packet)
+		}
+	}
+	if from <= 0 || len(backlog) == 0 {
+		return backlog, nil
+	}
+	packet, err := ReconstructBroadcastPacket(packets, backlog[0].Idx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(packet)
+	if err != nil {
+		return nil, err
+	}
+	backlog[0] = RealtimePacket{Idx: packet.Idx, Data: data}
+	return backlog, nil
+}
 
-		rb.writeGameFile(filename, logs)
-		rb.writeGamesListFile()
-		slog.Info("JSONLファイルにブロードキャストを保存しました", "game_id", packet.Id)
+func (rb *RealtimeBroadcaster) readEndedPackets(filename string, view string) ([]RealtimePacket, error) {
+	if view == RV_COMMENTATOR {
+		packets, err := readRealtimePackets(filepath.Join(rb.config.CommentatorDir, fmt.Sprintf("%s.jsonl", filename)))
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			return packets, err
+		}
 	}
+	return readRealtimePackets(filepath.Join(rb.config.OutputDir, fmt.Sprintf("%s.jsonl", filename)))
+}
+
+func readRealtimePackets(path string) ([]RealtimePacket, error) {
+	reader, err := util.OpenLogFile(path)
+	if err != nil {
+		reader, err = util.OpenLogFile(path + util.GzipExt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer reader.Close()
+
+	packets := []RealtimePacket{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var packet struct {
+			Idx int `json:"idx"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &packet); err != nil {
+			continue
+		}
+		packets = append(packets, RealtimePacket{Idx: packet.Idx, Data: slices.Clone(scanner.Bytes())})
+	}
+	return packets, scanner.Err()
 }
 
 func (rb *RealtimeBroadcaster) writeGamesListFile() {
@@ -122,11 +690,13 @@ func (rb *RealtimeBroadcaster) writeGamesListFile() {
 	items := make([]Item, 0)
 	rb.data.Range(func(_, value any) bool {
 		gameLog := value.(*RealtimeBroadcasterLog)
+		gameLog.mu.Lock()
 		item := Item{
 			ID:        gameLog.id,
 			Filename:  gameLog.filename,
 			UpdatedAt: gameLog.updatedAt,
 		}
+		gameLog.mu.Unlock()
 		items = append(items, item)
 		return true
 	})
@@ -137,19 +707,9 @@ func (rb *RealtimeBroadcaster) writeGamesListFile() {
 		return
 	}
 	filePath := filepath.Join(rb.config.OutputDir, "games.json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := writeFileAtomic(filePath, data); err != nil {
 		slog.Error("ゲーム一覧ファイルの作成に失敗しました", "error", err)
 		return
 	}
 	slog.Info("ゲーム一覧ファイルを更新しました", "path", filePath)
 }
-
-func (rb *RealtimeBroadcaster) writeGameFile(filename string, logs []string) {
-	filePath := filepath.Join(rb.config.OutputDir, fmt.Sprintf("%s.jsonl", filename))
-	content := strings.Join(logs, "\n")
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-		slog.Error("ゲームファイルの保存に失敗しました", "error", err, "path", filePath)
-		return
-	}
-	slog.Info("ゲームファイルを保存しました", "path", filePath)
-}
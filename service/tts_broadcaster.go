@@ -1,34 +1,36 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"math"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/aiwolfdial/aiwolf-nlp-server/locale"
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 	"github.com/grafov/m3u8"
 )
 
 const (
-	playlistFile = "playlist.m3u8"
+	playlistFile        = "playlist.m3u8"
+	announcementSpeaker = 23
+	defaultSpeaker      = 1
 )
 
 type TTSBroadcaster struct {
-	config  model.TTSBroadcasterConfig
-	baseURL *url.URL
-	client  *http.Client
-	streams sync.Map
+	config   model.TTSBroadcasterConfig
+	engine   TTSEngine
+	cache    *ttsCache
+	scope    string
+	blind    bool
+	language string
+	streams  sync.Map
 }
 
 type Stream struct {
@@ -36,26 +38,35 @@ type Stream struct {
 	lastSegmentTime int64
 	segmentCounter  int64
 	playlist        *m3u8.MediaPlaylist
+	subtitles       *m3u8.MediaPlaylist
+	elapsed         float64
 	playlistMu      sync.Mutex
 }
 
 func NewTTSBroadcaster(config model.Config) *TTSBroadcaster {
-	baseURL, err := url.Parse(config.TTSBroadcaster.Host)
+	engine, err := NewTTSEngine(config.TTSBroadcaster)
 	if err != nil {
-		slog.Error("音声合成サーバのURLの解析に失敗しました", "error", err)
-		baseURL = &url.URL{
-			Scheme: "http",
-			Host:   "localhost:50021",
-		}
+		slog.Error("音声合成エンジンの作成に失敗しました", "error", err)
+		return nil
 	}
-
-	return &TTSBroadcaster{
-		config:  config.TTSBroadcaster,
-		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: config.TTSBroadcaster.Timeout,
-		},
+	slog.Info("音声合成エンジンを作成しました", "engine", engine.Name())
+
+	t := &TTSBroadcaster{
+		config:   config.TTSBroadcaster,
+		engine:   engine,
+		scope:    ttsCacheScope(engine.Name(), config.TTSBroadcaster),
+		blind:    config.RealtimeBroadcaster.Blind,
+		language: config.Server.Language,
+	}
+	if config.TTSBroadcaster.CacheDir != "" {
+		cache, err := newTTSCache(config.TTSBroadcaster.CacheDir, config.TTSBroadcaster.CacheMaxSizeMB)
+		if err != nil {
+			slog.Error("音声合成キャッシュの初期化に失敗しました", "error", err)
+		} else {
+			t.cache = cache
+		}
 	}
+	return t
 }
 
 func (t *TTSBroadcaster) Start() {
@@ -64,6 +75,9 @@ func (t *TTSBroadcaster) Start() {
 		return
 	}
 	t.cleanupSegments()
+	if t.cache != nil {
+		t.prerenderAnnouncements()
+	}
 }
 
 func (t *TTSBroadcaster) getStream(id string) *Stream {
@@ -101,11 +115,22 @@ func (t *TTSBroadcaster) CreateStream(id string) {
 	playlist.Closed = false
 	stream.playlist = playlist
 
+	subtitles, err := m3u8.NewMediaPlaylist(math.MaxInt16, math.MaxInt16)
+	if err != nil {
+		slog.Error("字幕プレイリストの作成に失敗しました", "error", err, "id", id)
+		return
+	}
+	subtitles.TargetDuration = playlist.TargetDuration
+	subtitles.SetVersion(3)
+	subtitles.Closed = false
+	stream.subtitles = subtitles
+
 	if _, loaded := t.streams.LoadOrStore(id, stream); loaded {
 		return
 	}
 
 	t.writePlaylist(id, stream)
+	t.writeMasterPlaylist(id)
 	slog.Info("ストリームを作成しました", "id", id)
 }
 
@@ -138,21 +163,44 @@ func (t *TTSBroadcaster) writePlaylist(id string, stream *Stream) {
 	if err := os.WriteFile(playlistPath, stream.playlist.Encode().Bytes(), 0644); err != nil {
 		slog.Error("プレイリストの書き込みに失敗しました", "error", err, "id", id)
 	}
+	subtitlePath := filepath.Join(streamDir, subtitlePlaylistFile)
+	if err := os.WriteFile(subtitlePath, stream.subtitles.Encode().Bytes(), 0644); err != nil {
+		slog.Error("字幕プレイリストの書き込みに失敗しました", "error", err, "id", id)
+	}
 }
 
-func (t *TTSBroadcaster) BroadcastText(id string, text string, speaker int) {
+func (t *TTSBroadcaster) BroadcastText(id string, name string, text string, speaker int) {
 	if text == model.T_SKIP || text == model.T_OVER {
 		return
 	}
 
 	if t.config.Async {
-		t.broadcastTextAsync(id, text, speaker)
+		t.broadcastTextAsync(id, name, text, speaker)
 	} else {
-		t.broadcastText(id, text, speaker)
+		t.broadcastText(id, name, text, speaker)
+	}
+}
+
+func (t *TTSBroadcaster) OnGameEvent(state model.GameState, event model.GameEvent) {
+	switch e := event.(type) {
+	case model.GameStartEvent:
+		t.CreateStream(state.ID)
+		t.BroadcastText(state.ID, "", locale.T("ゲームが開始されました"), announcementSpeaker)
+	case model.TalkEvent:
+		if e.Whisper && t.blind {
+			return
+		}
+		speaker := defaultSpeaker
+		if e.Talk.Agent.Profile != nil {
+			speaker = e.Talk.Agent.Profile.VoiceID
+		}
+		t.BroadcastText(state.ID, speakerName(e.Talk.Agent), e.Talk.Text, speaker)
+	case model.GameEndEvent:
+		t.BroadcastText(state.ID, "", locale.T("ゲームが終了しました"), announcementSpeaker)
 	}
 }
 
-func (t *TTSBroadcaster) broadcastTextAsync(id string, text string, speaker int) {
+func (t *TTSBroadcaster) broadcastTextAsync(id string, name string, text string, speaker int) {
 	stream := t.getStream(id)
 	if stream == nil {
 		return
@@ -168,19 +216,13 @@ func (t *TTSBroadcaster) broadcastTextAsync(id string, text string, speaker int)
 		ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
 		defer cancel()
 
-		audioQuery, err := t.fetchAudioQuery(ctx, text, speaker)
-		if err != nil {
-			slog.Error("オーディオクエリの取得に失敗しました", "error", err, "id", id)
-			return
-		}
-
-		if _, err := t.synthesizeAndProcessAudio(ctx, audioQuery, id, stream, speaker); err != nil {
+		if _, err := t.synthesize(ctx, id, stream, name, text, speaker); err != nil {
 			slog.Error("音声合成に失敗しました", "error", err, "id", id)
 		}
 	}()
 }
 
-func (t *TTSBroadcaster) broadcastText(id string, text string, speaker int) {
+func (t *TTSBroadcaster) broadcastText(id string, name string, text string, speaker int) {
 	stream := t.getStream(id)
 	if stream == nil {
 		return
@@ -195,13 +237,7 @@ func (t *TTSBroadcaster) broadcastText(id string, text string, speaker int) {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
 	defer cancel()
 
-	audioQuery, err := t.fetchAudioQuery(ctx, text, speaker)
-	if err != nil {
-		slog.Error("オーディオクエリの取得に失敗しました", "error", err, "id", id)
-		return
-	}
-
-	duration, err := t.synthesizeAndProcessAudio(ctx, audioQuery, id, stream, speaker)
+	duration, err := t.synthesize(ctx, id, stream, name, text, speaker)
 	if err != nil {
 		slog.Error("音声合成に失敗しました", "error", err, "id", id)
 		return
@@ -210,70 +246,37 @@ func (t *TTSBroadcaster) broadcastText(id string, text string, speaker int) {
 	time.Sleep(time.Duration(duration * float64(time.Second)))
 }
 
-func (t *TTSBroadcaster) fetchAudioQuery(ctx context.Context, text string, speaker int) ([]byte, error) {
-	baseURL := *t.baseURL
-	baseURL.Path = "/audio_query"
-	params := url.Values{}
-	params.Add("speaker", fmt.Sprintf("%d", speaker))
-	params.Add("text", text)
-	baseURL.RawQuery = params.Encode()
-	queryURL := baseURL.String()
+func (t *TTSBroadcaster) synthesize(ctx context.Context, id string, stream *Stream, name string, text string, speaker int) (float64, error) {
+	counter := atomic.AddInt64(&stream.segmentCounter, 1)
+	baseName := fmt.Sprintf("segment_%d", counter-1)
+	streamDir := t.getSegmentDir(id)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", queryURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("オーディオクエリリクエスト作成に失敗しました: %w", err)
+	key := ttsCacheKey(t.scope, speaker, text)
+	if t.cache != nil {
+		if segments, ok := t.cache.Get(key, streamDir, baseName); ok {
+			slog.Info("音声合成キャッシュを使用しました", "id", id, "key", key)
+			return t.addSegmentsToPlaylist(id, stream, name, text, segments), nil
+		}
 	}
 
-	resp, err := t.client.Do(req)
+	segments, err := t.render(ctx, text, speaker, streamDir, baseName)
 	if err != nil {
-		return nil, fmt.Errorf("オーディオクエリ送信に失敗しました: %w", err)
+		return 0, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("オーディオクエリエラー: ステータスコード %d", resp.StatusCode)
-	}
-
-	queryParams, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("オーディオクエリ読み取りに失敗しました: %w", err)
+	if t.cache != nil {
+		if err := t.cache.Put(key, streamDir, segments); err != nil {
+			slog.Error("音声合成キャッシュの保存に失敗しました", "error", err, "key", key)
+		}
 	}
-
-	return queryParams, nil
+	return t.addSegmentsToPlaylist(id, stream, name, text, segments), nil
 }
 
-func (t *TTSBroadcaster) synthesizeAndProcessAudio(ctx context.Context, queryParams []byte, id string, stream *Stream, speaker int) (float64, error) {
-	baseURL := *t.baseURL
-	baseURL.Path = "/synthesis"
-	params := url.Values{}
-	params.Add("speaker", fmt.Sprintf("%d", speaker))
-	baseURL.RawQuery = params.Encode()
-	queryURL := baseURL.String()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", queryURL, bytes.NewBuffer(queryParams))
-	if err != nil {
-		return 0, fmt.Errorf("合成リクエスト作成に失敗しました: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := t.client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("合成リクエスト送信に失敗しました: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("合成エラー: ステータスコード %d", resp.StatusCode)
-	}
-
-	wavData, err := io.ReadAll(resp.Body)
+func (t *TTSBroadcaster) render(ctx context.Context, text string, speaker int, dir string, baseName string) ([]ttsSegment, error) {
+	wavData, err := t.engine.Synthesize(ctx, text, speaker)
 	if err != nil {
-		return 0, fmt.Errorf("合成データ読み取りに失敗しました: %w", err)
+		return nil, err
 	}
 
-	counter := atomic.AddInt64(&stream.segmentCounter, 1)
-	baseName := fmt.Sprintf("segment_%d", counter-1)
-
 	segmentParams := util.ConvertWavToSegmentParams{
 		FfmpegPath:      t.config.FfmpegPath,
 		FfprobePath:     t.config.FfprobePath,
@@ -284,46 +287,93 @@ func (t *TTSBroadcaster) synthesizeAndProcessAudio(ctx context.Context, queryPar
 		TempDir:         t.config.TempDir,
 		SegmentDuration: t.config.TargetDuration.Seconds(),
 		Data:            wavData,
-		BaseDir:         t.getSegmentDir(id),
+		BaseDir:         dir,
 		BaseName:        baseName,
 	}
 
 	segmentNames, err := util.ConvertWavToSegment(segmentParams)
 	if err != nil {
-		return 0, fmt.Errorf("WAVからセグメントへの変換に失敗しました: %w", err)
+		return nil, fmt.Errorf("WAVからセグメントへの変換に失敗しました: %w", err)
 	}
 
-	return t.addSegmentsToPlaylist(id, stream, segmentNames), nil
+	segments := make([]ttsSegment, 0, len(segmentNames))
+	for _, segmentName := range segmentNames {
+		duration, err := util.GetDuration(t.config.FfprobePath, t.config.DurationArgs, filepath.Join(dir, segmentName))
+		if err != nil {
+			slog.Error("セグメント再生時間の取得に失敗しました", "error", err, "segment", segmentName)
+			duration = t.config.TargetDuration.Seconds()
+		}
+		segments = append(segments, ttsSegment{Name: segmentName, Duration: duration})
+	}
+	return segments, nil
 }
 
-func (t *TTSBroadcaster) addSegmentsToPlaylist(id string, stream *Stream, segmentNames []string) float64 {
-	if len(segmentNames) == 0 {
-		return 0
+func (t *TTSBroadcaster) prerenderAnnouncements() {
+	for _, text := range []string{locale.T("ゲームが開始されました"), locale.T("ゲームが終了しました")} {
+		key := ttsCacheKey(t.scope, announcementSpeaker, text)
+		if t.cache.Has(key) {
+			continue
+		}
+		dir, err := os.MkdirTemp(t.config.TempDir, "announcement")
+		if err != nil {
+			slog.Error("アナウンスの事前生成に失敗しました", "error", err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+		segments, err := t.render(ctx, text, announcementSpeaker, dir, "announcement")
+		cancel()
+		if err == nil {
+			err = t.cache.Put(key, dir, segments)
+		}
+		os.RemoveAll(dir)
+		if err != nil {
+			slog.Error("アナウンスの事前生成に失敗しました", "error", err)
+			continue
+		}
+		slog.Info("アナウンスを事前生成しました", "text", text)
 	}
+}
 
-	streamDir := t.getSegmentDir(id)
+func (t *TTSBroadcaster) addSegmentsToPlaylist(id string, stream *Stream, name string, text string, segments []ttsSegment) float64 {
+	if len(segments) == 0 {
+		return 0
+	}
 
 	stream.playlistMu.Lock()
 	defer stream.playlistMu.Unlock()
 
 	var totalDuration float64
-	for _, segmentName := range segmentNames {
-		segmentPath := filepath.Join(streamDir, segmentName)
-		duration, err := util.GetDuration(t.config.FfprobePath, t.config.DurationArgs, segmentPath)
-		if err != nil {
-			slog.Error("セグメント再生時間の取得に失敗しました", "error", err, "id", id, "segment", segmentName)
-			duration = t.config.TargetDuration.Seconds()
-		}
-
-		totalDuration += duration
+	for _, segment := range segments {
+		totalDuration += segment.Duration
+	}
+	cue := subtitleCue{
+		Start: stream.elapsed,
+		End:   stream.elapsed + totalDuration,
+		Name:  name,
+		Text:  text,
+	}
 
+	for _, segment := range segments {
 		if err := stream.playlist.AppendSegment(&m3u8.MediaSegment{
-			URI:      segmentName,
-			Duration: duration,
+			URI:      segment.Name,
+			Duration: segment.Duration,
+		}); err != nil {
+			slog.Error("プレイリストへのセグメント追加に失敗しました", "error", err, "id", id, "segment", segment.Name)
+		}
+
+		subtitleName, err := t.writeSubtitleSegment(id, segment.Name, cue)
+		if err != nil {
+			slog.Error("字幕セグメントの書き込みに失敗しました", "error", err, "id", id, "segment", segment.Name)
+			subtitleName = emptySubtitleFile
+		}
+		if err := stream.subtitles.AppendSegment(&m3u8.MediaSegment{
+			URI:      subtitleName,
+			Duration: segment.Duration,
 		}); err != nil {
-			slog.Error("プレイリストへのセグメント追加に失敗しました", "error", err, "id", id, "segment", segmentName)
+			slog.Error("字幕プレイリストへのセグメント追加に失敗しました", "error", err, "id", id, "segment", subtitleName)
 		}
 	}
+	stream.elapsed += totalDuration
 
 	t.writePlaylist(id, stream)
 	return totalDuration
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+const ttsCacheManifest = "manifest.json"
+
+type ttsSegment struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration"`
+}
+
+type ttsCacheEntry struct {
+	segments []ttsSegment
+	size     int64
+	lastUsed time.Time
+}
+
+type ttsCache struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+	entries map[string]*ttsCacheEntry
+	total   int64
+}
+
+func newTTSCache(dir string, maxSizeMB int64) (*ttsCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	cache := &ttsCache{
+		dir:     dir,
+		maxSize: maxSizeMB * 1024 * 1024,
+		entries: make(map[string]*ttsCacheEntry),
+	}
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(d.Name(), tempSuffix) {
+			os.RemoveAll(filepath.Join(dir, d.Name()))
+			continue
+		}
+		entry, err := cache.load(d.Name())
+		if err != nil {
+			slog.Warn("音声合成キャッシュの読み込みに失敗したため、削除します", "key", d.Name(), "error", err)
+			os.RemoveAll(filepath.Join(dir, d.Name()))
+			continue
+		}
+		cache.entries[d.Name()] = entry
+		cache.total += entry.size
+	}
+	cache.mu.Lock()
+	cache.evict()
+	cache.mu.Unlock()
+	slog.Info("音声合成キャッシュを初期化しました", "dir", dir, "entries", len(cache.entries), "size", cache.total)
+	return cache, nil
+}
+
+func ttsCacheKey(scope string, speaker int, text string) string {
+	hash := sha256.Sum256([]byte(scope + "\x00" + strconv.Itoa(speaker) + "\x00" + text))
+	return hex.EncodeToString(hash[:])
+}
+
+// エンジンの接続先や変換の設定が変わった場合に古い音声を再利用しないよう、キーに含める設定をまとめる
+func ttsCacheScope(engine string, config model.TTSBroadcasterConfig) string {
+	data, _ := json.Marshal([]any{
+		engine,
+		config.Host,
+		config.Command,
+		config.ConvertArgs,
+		config.PreConvertArgs,
+		config.SplitArgs,
+		config.TargetDuration.Seconds(),
+	})
+	return string(data)
+}
+
+func (c *ttsCache) load(key string) (*ttsCacheEntry, error) {
+	manifestPath := filepath.Join(c.dir, key, ttsCacheManifest)
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	entry := &ttsCacheEntry{lastUsed: info.ModTime()}
+	if err := json.Unmarshal(data, &entry.segments); err != nil {
+		return nil, err
+	}
+	for _, segment := range entry.segments {
+		info, err := os.Stat(filepath.Join(c.dir, key, segment.Name))
+		if err != nil {
+			return nil, err
+		}
+		entry.size += info.Size()
+	}
+	return entry, nil
+}
+
+func (c *ttsCache) Has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, exists := c.entries[key]
+	return exists
+}
+
+func (c *ttsCache) Get(key string, dstDir string, baseName string) ([]ttsSegment, bool) {
+	c.mu.Lock()
+	entry, exists := c.entries[key]
+	if !exists {
+		c.mu.Unlock()
+		return nil, false
+	}
+	cached := slices.Clone(entry.segments)
+	lastUsed := time.Now()
+	entry.lastUsed = lastUsed
+	c.mu.Unlock()
+	os.Chtimes(filepath.Join(c.dir, key, ttsCacheManifest), lastUsed, lastUsed)
+
+	segments := make([]ttsSegment, 0, len(cached))
+	for i, segment := range cached {
+		name := fmt.Sprintf("%s_%d.ts", baseName, i)
+		if err := util.CopyFile(filepath.Join(c.dir, key, segment.Name), filepath.Join(dstDir, name)); err != nil {
+			slog.Warn("音声合成キャッシュのコピーに失敗しました", "key", key, "error", err)
+			return nil, false
+		}
+		segments = append(segments, ttsSegment{Name: name, Duration: segment.Duration})
+	}
+	return segments, true
+}
+
+func (c *ttsCache) Put(key string, srcDir string, segments []ttsSegment) error {
+	if c.Has(key) {
+		return nil
+	}
+	tempDir, err := os.MkdirTemp(c.dir, key+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	entry := &ttsCacheEntry{lastUsed: time.Now()}
+	for i, segment := range segments {
+		name := fmt.Sprintf("%d.ts", i)
+		if err := util.CopyFile(filepath.Join(srcDir, segment.Name), filepath.Join(tempDir, name)); err != nil {
+			return err
+		}
+		info, err := os.Stat(filepath.Join(tempDir, name))
+		if err != nil {
+			return err
+		}
+		entry.segments = append(entry.segments, ttsSegment{Name: name, Duration: segment.Duration})
+		entry.size += info.Size()
+	}
+	data, err := json.Marshal(entry.segments)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ttsCacheManifest), data, 0644); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; exists {
+		return nil
+	}
+	if err := os.Rename(tempDir, filepath.Join(c.dir, key)); err != nil {
+		return err
+	}
+	c.entries[key] = entry
+	c.total += entry.size
+	c.evict()
+	return nil
+}
+
+func (c *ttsCache) evict() {
+	if c.maxSize <= 0 || c.total <= c.maxSize {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return c.entries[a].lastUsed.Compare(c.entries[b].lastUsed)
+	})
+	for _, key := range keys {
+		if c.total <= c.maxSize {
+			break
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, key)); err != nil {
+			slog.Error("音声合成キャッシュの削除に失敗しました", "key", key, "error", err)
+			continue
+		}
+		c.total -= c.entries[key].size
+		delete(c.entries, key)
+		slog.Info("容量の上限を超えた音声合成キャッシュを削除しました", "key", key)
+	}
+}
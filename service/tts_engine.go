@@ -0,0 +1,243 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+const (
+	TE_VOICEVOX = "voicevox"
+	TE_HTTP     = "http"
+	TE_COMMAND  = "command"
+	TE_STUB     = "stub"
+)
+
+const (
+	stubSampleRate      = 24000
+	stubSecondsPerRune  = 0.1
+	stubMinimumDuration = 0.5
+)
+
+type TTSEngine interface {
+	Name() string
+	Synthesize(ctx context.Context, text string, speaker int) ([]byte, error)
+}
+
+func NewTTSEngine(config model.TTSBroadcasterConfig) (TTSEngine, error) {
+	client := &http.Client{
+		Timeout: config.Timeout,
+	}
+	switch config.Engine {
+	case "", TE_VOICEVOX:
+		baseURL, err := url.Parse(config.Host)
+		if err != nil {
+			return nil, fmt.Errorf("音声合成サーバのURLの解析に失敗しました: %w", err)
+		}
+		return &voicevoxEngine{baseURL: baseURL, client: client}, nil
+	case TE_HTTP:
+		endpoint, err := url.Parse(config.Host)
+		if err != nil {
+			return nil, fmt.Errorf("音声合成サーバのURLの解析に失敗しました: %w", err)
+		}
+		return &httpEngine{endpoint: endpoint, client: client}, nil
+	case TE_COMMAND:
+		if len(config.Command) == 0 {
+			return nil, errors.New("音声合成コマンドが指定されていません")
+		}
+		return &commandEngine{command: config.Command}, nil
+	case TE_STUB:
+		return &stubEngine{}, nil
+	}
+	return nil, errors.New("不明な音声合成エンジンです: " + config.Engine)
+}
+
+type voicevoxEngine struct {
+	baseURL *url.URL
+	client  *http.Client
+}
+
+func (e *voicevoxEngine) Name() string {
+	return TE_VOICEVOX
+}
+
+func (e *voicevoxEngine) Synthesize(ctx context.Context, text string, speaker int) ([]byte, error) {
+	audioQuery, err := e.fetchAudioQuery(ctx, text, speaker)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := *e.baseURL
+	baseURL.Path = "/synthesis"
+	params := url.Values{}
+	params.Add("speaker", fmt.Sprintf("%d", speaker))
+	baseURL.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL.String(), bytes.NewBuffer(audioQuery))
+	if err != nil {
+		return nil, fmt.Errorf("合成リクエスト作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("合成リクエスト送信に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("合成エラー: ステータスコード %d", resp.StatusCode)
+	}
+
+	wavData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("合成データ読み取りに失敗しました: %w", err)
+	}
+	return wavData, nil
+}
+
+func (e *voicevoxEngine) fetchAudioQuery(ctx context.Context, text string, speaker int) ([]byte, error) {
+	baseURL := *e.baseURL
+	baseURL.Path = "/audio_query"
+	params := url.Values{}
+	params.Add("speaker", fmt.Sprintf("%d", speaker))
+	params.Add("text", text)
+	baseURL.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("オーディオクエリリクエスト作成に失敗しました: %w", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("オーディオクエリ送信に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("オーディオクエリエラー: ステータスコード %d", resp.StatusCode)
+	}
+
+	queryParams, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("オーディオクエリ読み取りに失敗しました: %w", err)
+	}
+	return queryParams, nil
+}
+
+type httpEngine struct {
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func (e *httpEngine) Name() string {
+	return TE_HTTP
+}
+
+func (e *httpEngine) Synthesize(ctx context.Context, text string, speaker int) ([]byte, error) {
+	body, err := json.Marshal(map[string]any{
+		"text":    text,
+		"speaker": speaker,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("合成リクエスト作成に失敗しました: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("合成リクエスト作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/wav")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("合成リクエスト送信に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("合成エラー: ステータスコード %d", resp.StatusCode)
+	}
+
+	wavData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("合成データ読み取りに失敗しました: %w", err)
+	}
+	return wavData, nil
+}
+
+type commandEngine struct {
+	command []string
+}
+
+func (e *commandEngine) Name() string {
+	return TE_COMMAND
+}
+
+func (e *commandEngine) Synthesize(ctx context.Context, text string, speaker int) ([]byte, error) {
+	args := make([]string, 0, len(e.command)-1)
+	for _, arg := range e.command[1:] {
+		args = append(args, strings.ReplaceAll(arg, "{speaker}", strconv.Itoa(speaker)))
+	}
+	cmd := exec.CommandContext(ctx, e.command[0], args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	wavData, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("音声合成コマンドの実行に失敗しました: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return wavData, nil
+}
+
+type stubEngine struct{}
+
+func (e *stubEngine) Name() string {
+	return TE_STUB
+}
+
+func (e *stubEngine) Synthesize(ctx context.Context, text string, speaker int) ([]byte, error) {
+	duration := max(float64(utf8.RuneCountInString(text))*stubSecondsPerRune, stubMinimumDuration)
+	return silentWav(duration, stubSampleRate), nil
+}
+
+func silentWav(duration float64, sampleRate int) []byte {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	blockAlign := channels * bitsPerSample / 8
+	dataSize := int(duration*float64(sampleRate)) * blockAlign
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/grafov/m3u8"
+)
+
+const (
+	subtitlePlaylistFile = "subtitles.m3u8"
+	masterPlaylistFile   = "master.m3u8"
+	emptySubtitleFile    = "empty.vtt"
+	subtitleGroupID      = "subs"
+	masterBandwidth      = 128000
+)
+
+var subtitleEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r\n", " ", "\n", " ", "\r", " ")
+
+type subtitleCue struct {
+	Start float64
+	End   float64
+	Name  string
+	Text  string
+}
+
+func speakerName(agent model.Agent) string {
+	if agent.Profile != nil && agent.Profile.Name != "" {
+		return agent.Profile.Name
+	}
+	return agent.GameName
+}
+
+func (c subtitleCue) String() string {
+	text := subtitleEscaper.Replace(c.Text)
+	if c.Name != "" {
+		text = fmt.Sprintf("<v %s>%s: %s</v>", subtitleEscaper.Replace(c.Name), subtitleEscaper.Replace(c.Name), text)
+	}
+	return fmt.Sprintf("%s --> %s\n%s\n", formatVTTTimestamp(c.Start), formatVTTTimestamp(c.End), text)
+}
+
+func formatVTTTimestamp(seconds float64) string {
+	millis := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
+
+func (t *TTSBroadcaster) writeSubtitleSegment(id string, segmentName string, cue subtitleCue) (string, error) {
+	name := strings.TrimSuffix(segmentName, filepath.Ext(segmentName)) + ".vtt"
+	// 音声セグメントごとに時刻の基準が変わらないよう、ストリーム先頭を基準とした時刻で記述する
+	content := "WEBVTT\nX-TIMESTAMP-MAP=MPEGTS:0,LOCAL:00:00:00.000\n\n" + cue.String()
+	return name, os.WriteFile(filepath.Join(t.getSegmentDir(id), name), []byte(content), 0644)
+}
+
+func (t *TTSBroadcaster) writeMasterPlaylist(id string) {
+	language := t.language
+	if language == "" {
+		language = "ja"
+	}
+	master := m3u8.NewMasterPlaylist()
+	master.Append(playlistFile, nil, m3u8.VariantParams{
+		Bandwidth: masterBandwidth,
+		Subtitles: subtitleGroupID,
+		Alternatives: []*m3u8.Alternative{{
+			GroupId:    subtitleGroupID,
+			Type:       "SUBTITLES",
+			URI:        subtitlePlaylistFile,
+			Language:   language,
+			Name:       language,
+			Default:    true,
+			Autoselect: "YES",
+		}},
+	})
+
+	// 字幕セグメントの書き込みに失敗した場合に音声とずれないよう、空の字幕セグメントを用意しておく
+	emptyPath := filepath.Join(t.getSegmentDir(id), emptySubtitleFile)
+	if err := os.WriteFile(emptyPath, []byte("WEBVTT\n"), 0644); err != nil {
+		slog.Error("空の字幕セグメントの書き込みに失敗しました", "error", err, "id", id)
+	}
+
+	masterPath := filepath.Join(t.getSegmentDir(id), masterPlaylistFile)
+	if err := os.WriteFile(masterPath, master.Encode().Bytes(), 0644); err != nil {
+		slog.Error("マスタープレイリストの書き込みに失敗しました", "error", err, "id", id)
+	}
+}
@@ -121,15 +121,19 @@ func TestAttackPhase5(t *testing.T) {
 func executeAttackPhase(t *testing.T, targetMap map[string]string, expectStatuses []map[string]model.Status, config *model.Config) {
 	nameMap := make(map[string]string)
 	var mu sync.Mutex
+	var initialized sync.WaitGroup
+	initialized.Add(config.Game.AgentCount)
 
 	handlers := map[model.Request]func(tc TestClient) (string, error){
 		model.R_INITIALIZE: func(tc TestClient) (string, error) {
 			mu.Lock()
 			nameMap[tc.originalName] = tc.gameName
 			mu.Unlock()
+			initialized.Done()
 			return "", nil
 		},
 		model.R_ATTACK: func(tc TestClient) (string, error) {
+			initialized.Wait()
 			mu.Lock()
 			target := nameMap[targetMap[tc.originalName]]
 			mu.Unlock()
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestExportDataset(t *testing.T) {
+	config := model.Config{}
+	config.JSONLogger.Enable = true
+	config.JSONLogger.OutputDir = t.TempDir()
+
+	data, err := json.Marshal(map[string]any{
+		"game_id":  "game",
+		"win_side": "WEREWOLF",
+		"agents": []map[string]any{
+			{"idx": 1, "team": "alpha", "name": "alpha1", "game_name": "Agent[01]", "role": "SEER"},
+			{"idx": 2, "team": "beta", "name": "beta1", "game_name": "Agent[02]", "role": "WEREWOLF"},
+		},
+		"entries": []map[string]any{
+			{"agent": "Agent[01]", "request": `{"request":"NAME"}`, "response": "alpha"},
+			{"agent": "Agent[02]", "request": `{"request":"NAME"}`, "response": "beta"},
+			{"agent": "Agent[01]", "request": `{"request":"TALK","info":{"day":1}}`, "response": "Over"},
+			{"agent": "Agent[02]", "request": `{"request":"TALK","info":{"day":1}}`, "error": model.ErrResponseTimeout.Error()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ログのJSON化に失敗しました: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(config.JSONLogger.OutputDir, "game.json"), data, 0644); err != nil {
+		t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "dataset.jsonl")
+	if err := core.ExportDataset(config, path, core.DatasetOptions{Outcomes: []string{core.DO_LOSE}, Anonymize: true}); err != nil {
+		t.Fatalf("データセットの出力に失敗しました: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("データセットの読み込みに失敗しました: %v", err)
+	}
+	defer file.Close()
+
+	records := []core.DatasetRecord{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record core.DatasetRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("データセットのパースに失敗しました: %v", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("レコード数が一致しません: %d", len(records))
+	}
+	name := records[0]
+	if name.Agent != "Agent[01]" || name.Team != "team01" || name.Name != "team011" || name.Response != "team011" || name.Outcome != core.DO_LOSE {
+		t.Errorf("レコードが一致しません: %+v", name)
+	}
+	if name.Roles["Agent[02]"] != "WEREWOLF" || string(records[1].Packet) != `{"request":"TALK","info":{"day":1}}` {
+		t.Errorf("レコードが一致しません: %+v", records[1])
+	}
+
+	if err := core.ExportDataset(config, path, core.DatasetOptions{Outcomes: []string{"draw"}}); err == nil {
+		t.Errorf("不明な勝敗でエラーが発生しませんでした")
+	}
+}
@@ -41,6 +41,8 @@ func TestDivinePhase3(t *testing.T) {
 func executeDivinePhase(t *testing.T, targetRole model.Role, expectSpecies model.Species, config *model.Config) {
 	roleMapping := make(map[model.Role][]string)
 	var mu sync.Mutex
+	var initialized sync.WaitGroup
+	initialized.Add(config.Game.AgentCount)
 
 	handlers := map[model.Request]func(tc TestClient) (string, error){
 		model.R_INITIALIZE: func(tc TestClient) (string, error) {
@@ -52,9 +54,11 @@ func executeDivinePhase(t *testing.T, targetRole model.Role, expectSpecies model
 				}
 				mu.Unlock()
 			}
+			initialized.Done()
 			return "", nil
 		},
 		model.R_DIVINE: func(tc TestClient) (string, error) {
+			initialized.Wait()
 			mu.Lock()
 			defer mu.Unlock()
 			if gameNames, exists := roleMapping[targetRole]; exists {
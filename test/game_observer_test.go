@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+type recordingObserver struct {
+	mu     *sync.Mutex
+	events *[]string
+}
+
+func (o recordingObserver) OnGameEvent(state model.GameState, event model.GameEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	*o.events = append(*o.events, string(event.Type()))
+}
+
+type recordingHandler struct {
+	mu     *sync.Mutex
+	events *[]string
+}
+
+func (h recordingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h
+}
+
+func (h recordingHandler) WithGroup(name string) slog.Handler {
+	return h
+}
+
+func (h recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.events = append(*h.events, record.Message)
+	return nil
+}
+
+type silentReplayer struct{}
+
+func (silentReplayer) Respond(agent *model.Agent, packet model.Packet) (string, error) {
+	if packet.Request.Type == model.R_TALK.Type || packet.Request.Type == model.R_WHISPER.Type {
+		return model.T_OVER, nil
+	}
+	return "", nil
+}
+
+func (silentReplayer) Order(request model.Request, agents []*model.Agent) {}
+
+func (silentReplayer) Select(request model.Request, day int, candidates []model.Agent) model.Agent {
+	return candidates[0]
+}
+
+func TestGameObserver(t *testing.T) {
+	config, err := model.LoadFromPath("./config/full5.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDay = 0
+	setting, err := model.NewSetting(*config)
+	if err != nil {
+		t.Fatalf("設定の作成に失敗しました: %v", err)
+	}
+
+	var mu sync.Mutex
+	events := []string{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(recordingHandler{mu: &mu, events: &events}))
+	defer slog.SetDefault(defaultLogger)
+
+	roles := []model.Role{model.R_WEREWOLF, model.R_POSSESSED, model.R_SEER, model.R_VILLAGER, model.R_VILLAGER}
+	agents := make([]*model.Agent, 0, len(roles))
+	for i, role := range roles {
+		agents = append(agents, &model.Agent{Idx: i + 1, TeamName: "team", OriginalName: "team", GameName: fmt.Sprintf("Agent[%02d]", i+1), Role: role})
+	}
+	game := logic.NewReplayGame(config, setting, "observer", agents, silentReplayer{})
+	game.AddObserver(recordingObserver{mu: &mu, events: &events})
+	game.Start()
+
+	mu.Lock()
+	defer mu.Unlock()
+	types := slices.DeleteFunc(slices.Clone(events), func(event string) bool {
+		return !slices.Contains([]string{string(model.GE_START), string(model.GE_END), "エージェントをクローズしました"}, event)
+	})
+	expected := []string{string(model.GE_START), string(model.GE_END)}
+	for range agents {
+		expected = append(expected, "エージェントをクローズしました")
+	}
+	if !slices.Equal(expected, types) {
+		t.Errorf("イベントの順序が一致しません: %v", types)
+	}
+
+	gameEvents := slices.DeleteFunc(slices.Clone(events), func(event string) bool {
+		return event == "エージェントをクローズしました" || !slices.Contains([]model.GameEventType{
+			model.GE_START, model.GE_REQUEST_START, model.GE_REQUEST_END, model.GE_PHASE_CHANGE, model.GE_TALK, model.GE_VOTE,
+			model.GE_EXECUTION, model.GE_ATTACK, model.GE_DIVINE, model.GE_GUARD, model.GE_END,
+		}, model.GameEventType(event))
+	})
+	for _, eventType := range []model.GameEventType{model.GE_REQUEST_START, model.GE_REQUEST_END, model.GE_PHASE_CHANGE, model.GE_TALK} {
+		if !slices.Contains(gameEvents, string(eventType)) {
+			t.Errorf("イベントが通知されていません: %s", eventType)
+		}
+	}
+	if len(gameEvents) < 2 || gameEvents[len(gameEvents)-2] != string(model.GE_REQUEST_END) {
+		t.Errorf("終了イベントの直前がリクエストの完了ではありません: %v", gameEvents)
+	}
+}
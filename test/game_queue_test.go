@@ -0,0 +1,132 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestGameQueue(t *testing.T) {
+	config := model.Config{}
+	config.Server.Concurrency.MaxGames = 2
+	config.Server.Concurrency.MaxGamesPerTeam = 1
+	config.Server.Concurrency.MaxQueuedGames = 1
+	gq := core.NewGameQueue(config)
+
+	started := make(chan string, 4)
+	finish := map[string]chan struct{}{}
+	enqueue := func(id string, teams ...string) error {
+		agents := []*model.Agent{}
+		for i, team := range teams {
+			agents = append(agents, &model.Agent{Idx: i + 1, TeamName: team})
+		}
+		finish[id] = make(chan struct{})
+		done := finish[id]
+		return gq.Enqueue(logic.NewReplayGame(&config, nil, id, agents, nil), func() {
+			started <- id
+			<-done
+		})
+	}
+	waitStarted := func(expected string) {
+		select {
+		case id := <-started:
+			if id != expected {
+				t.Fatalf("開始されたゲームが一致しません: %s", id)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("ゲームが開始されませんでした: %s", expected)
+		}
+	}
+
+	if err := enqueue("game1", "alpha", "bravo"); err != nil {
+		t.Fatalf("ゲームの追加に失敗しました: %v", err)
+	}
+	waitStarted("game1")
+	if err := enqueue("game2", "alpha", "charlie"); err != nil {
+		t.Fatalf("ゲームの追加に失敗しました: %v", err)
+	}
+	if err := enqueue("game3", "delta", "echo"); err != nil {
+		t.Fatalf("ゲームの追加に失敗しました: %v", err)
+	}
+	waitStarted("game3")
+	if err := enqueue("game4", "foxtrot"); !errors.Is(err, core.ErrGameQueueFull) {
+		t.Errorf("キューの上限を超えたゲームが追加されました: %v", err)
+	}
+
+	status := gq.Status()
+	if status.Running != 2 || len(status.Queued) != 1 || status.Queued[0].ID != "game2" {
+		t.Errorf("キューの状態が一致しません: %+v", status)
+	}
+	if status.RunningTeams["alpha"] != 1 || status.RunningTeams["charlie"] != 0 {
+		t.Errorf("チームごとの実行数が一致しません: %+v", status.RunningTeams)
+	}
+	if !gq.IsFull() {
+		t.Errorf("キューが上限に達していません")
+	}
+
+	close(finish["game3"])
+	select {
+	case id := <-started:
+		t.Fatalf("チームの上限を超えてゲームが開始されました: %s", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(finish["game1"])
+	waitStarted("game2")
+	if status := gq.Status(); status.Running != 1 || len(status.Queued) != 0 || status.RunningTeams["bravo"] != 0 {
+		t.Errorf("ゲーム終了後のキューの状態が一致しません: %+v", status)
+	}
+	close(finish["game2"])
+}
+
+func TestGameQueueRelease(t *testing.T) {
+	config := model.Config{}
+	config.Server.Concurrency.MaxGames = 1
+	config.Server.Concurrency.MaxQueuedGames = 1
+	gq := core.NewGameQueue(config)
+
+	started := make(chan string, 4)
+	finish := make(chan struct{})
+	enqueue := func(id string) error {
+		return gq.Enqueue(logic.NewReplayGame(&config, nil, id, []*model.Agent{{Idx: 1, TeamName: id}}, nil), func() {
+			started <- id
+			<-finish
+		})
+	}
+	waiting := []string{"game3"}
+	var mu sync.Mutex
+	gq.OnRelease(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(waiting) > 0 && !gq.IsFull() {
+			if err := enqueue(waiting[0]); err == nil {
+				waiting = waiting[1:]
+			}
+		}
+	})
+
+	if err := enqueue("game1"); err != nil {
+		t.Fatalf("ゲームの追加に失敗しました: %v", err)
+	}
+	if err := enqueue("game2"); err != nil {
+		t.Fatalf("ゲームの追加に失敗しました: %v", err)
+	}
+	if err := enqueue("game3"); !errors.Is(err, core.ErrGameQueueFull) {
+		t.Fatalf("キューの上限を超えたゲームが追加されました: %v", err)
+	}
+	for _, expected := range []string{"game1", "game2", "game3"} {
+		select {
+		case id := <-started:
+			if id != expected {
+				t.Fatalf("開始されたゲームが一致しません: %s", id)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("ゲームの終了後に待機中のマッチが開始されませんでした: %s", expected)
+		}
+		finish <- struct{}{}
+	}
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestGameLogRoundTrip(t *testing.T) {
+	events := []gamelog.Event{
+		gamelog.StatusEvent{Day: 0, Idx: 1, Role: model.R_SEER, Status: model.S_ALIVE, OriginalName: "kanolab1", GameName: "Agent[01]"},
+		gamelog.TalkEvent{Day: 1, Idx: 0, Turn: 0, AgentIdx: 1, Text: "Hello, \"World\"!\n>>Agent[02]"},
+		gamelog.TalkEvent{Day: 1, Whisper: true, Idx: 0, Turn: 0, AgentIdx: 2, Text: "Over"},
+		gamelog.VoteEvent{Day: 1, AgentIdx: 1, TargetIdx: 2},
+		gamelog.VoteEvent{Day: 1, Attack: true, AgentIdx: 2, TargetIdx: 1},
+		gamelog.ExecuteEvent{Day: 1, AgentIdx: 2, Role: model.R_WEREWOLF},
+		gamelog.DivineEvent{Day: 1, AgentIdx: 1, TargetIdx: 2, Result: model.S_WEREWOLF},
+		gamelog.GuardEvent{Day: 1, AgentIdx: 3, TargetIdx: 1, Role: model.R_SEER},
+		gamelog.AttackEvent{Day: 1, TargetIdx: -1, Success: true},
+		gamelog.ResultEvent{Day: 1, Villagers: 3, Werewolves: 0, WinSide: model.T_VILLAGER},
+	}
+	lines := []string{gamelog.Header()}
+	for _, event := range events {
+		lines = append(lines, gamelog.Format(event))
+	}
+	reader, err := gamelog.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("ゲームログの読み込みに失敗しました: %v", err)
+	}
+	if reader.Version != gamelog.Version {
+		t.Fatalf("バージョンが一致しません: %d", reader.Version)
+	}
+	actual, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ゲームログのパースに失敗しました: %v", err)
+	}
+	if len(actual) != len(events) {
+		t.Fatalf("イベント数が一致しません: expected=%d, actual=%d", len(events), len(actual))
+	}
+	for i := range events {
+		if actual[i] != events[i] {
+			t.Errorf("イベントが一致しません: expected=%v, actual=%v", events[i], actual[i])
+		}
+	}
+}
+
+func TestGameLogLegacy(t *testing.T) {
+	log := "0,status,1,SEER,ALIVE,kanolab1,Agent[01]\n1,talk,0,0,1,Hello, World!\n1,result,3,0,VILLAGER"
+	reader, err := gamelog.NewReader(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ゲームログの読み込みに失敗しました: %v", err)
+	}
+	if reader.Version != 1 {
+		t.Fatalf("バージョンが一致しません: %d", reader.Version)
+	}
+	events, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("ゲームログのパースに失敗しました: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("イベント数が一致しません: %d", len(events))
+	}
+	if talk, ok := events[1].(gamelog.TalkEvent); !ok || talk.Text != "Hello, World!" {
+		t.Errorf("発言内容が一致しません: %v", events[1])
+	}
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/locale"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestLocale(t *testing.T) {
+	if err := locale.SetLanguage(locale.L_EN); err != nil {
+		t.Fatalf("言語の設定に失敗しました: %v", err)
+	}
+	t.Cleanup(func() {
+		locale.SetLanguage(locale.L_JA)
+	})
+
+	if locale.T("ゲームが開始されました") != "The game has started" {
+		t.Errorf("メッセージが翻訳されていません: %s", locale.T("ゲームが開始されました"))
+	}
+	if model.BE_END.Label() != "End" {
+		t.Errorf("イベントの表示名が翻訳されていません: %s", model.BE_END.Label())
+	}
+	wrapped := errors.New("ゲームログの日付が不正です: 未対応のゲームログのバージョンです: 3")
+	if locale.T(wrapped.Error()) != "Invalid day in game log: Unsupported game log version: 3" {
+		t.Errorf("ラップされたエラーが翻訳されていません: %s", locale.T(wrapped.Error()))
+	}
+	var buf bytes.Buffer
+	logger := slog.New(locale.NewHandler(slog.NewTextHandler(&buf, nil))).With("error", errors.New("ゲームが開始されました"))
+	logger.Info("ゲームが終了しました")
+	if !strings.Contains(buf.String(), `msg="The game has ended"`) || !strings.Contains(buf.String(), `error="The game has started"`) {
+		t.Errorf("ログの属性が翻訳されていません: %s", buf.String())
+	}
+	if err := locale.SetLanguage("fr"); err == nil {
+		t.Errorf("不明な言語でエラーが発生しませんでした")
+	}
+}
+
+func TestLocaleCatalog(t *testing.T) {
+	if err := locale.SetLanguage(locale.L_EN); err != nil {
+		t.Fatalf("言語の設定に失敗しました: %v", err)
+	}
+	t.Cleanup(func() {
+		locale.SetLanguage(locale.L_JA)
+	})
+
+	functions := map[string]bool{
+		"slog.Debug": true, "slog.Info": true, "slog.Warn": true, "slog.Error": true,
+		"errors.New": true, "fmt.Errorf": true, "locale.T": true,
+	}
+	err := filepath.WalkDir("..", func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "test" || strings.HasPrefix(d.Name(), ".")) && path != ".." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			pkg, ok := selector.X.(*ast.Ident)
+			if !ok || !functions[pkg.Name+"."+selector.Sel.Name] {
+				return true
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+			message, _ := strconv.Unquote(literal.Value)
+			key, _, _ := strings.Cut(message, ": ")
+			if !isASCII(key) && locale.T(key) == key {
+				t.Errorf("翻訳が登録されていません: %s (%s)", key, path)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ソースファイルの走査に失敗しました: %v", err)
+	}
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/service"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+func TestLogArchiver(t *testing.T) {
+	config := model.Config{}
+	config.GameLogger.Enable = true
+	config.GameLogger.OutputDir = t.TempDir()
+	config.RealtimeBroadcaster.Enable = true
+	config.RealtimeBroadcaster.OutputDir = t.TempDir()
+	config.LogArchive.Enable = true
+	config.LogArchive.OutputDir = t.TempDir()
+	config.LogArchive.MaxAge = 30 * 24 * time.Hour
+
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	content := gamelog.Header() + "\n" + gamelog.Format(gamelog.ResultEvent{Day: 1, Villagers: 3, Werewolves: 0, WinSide: model.T_VILLAGER})
+	for _, name := range []string{"old.log", "new.log"} {
+		if err := os.WriteFile(filepath.Join(config.GameLogger.OutputDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+		}
+	}
+	os.Chtimes(filepath.Join(config.GameLogger.OutputDir, "old.log"), yesterday, yesterday)
+	summary := filepath.Join(config.GameLogger.OutputDir, "old.summary.json")
+	if err := os.WriteFile(summary, []byte("{}"), 0644); err != nil {
+		t.Fatalf("ゲームサマリーの作成に失敗しました: %v", err)
+	}
+	os.Chtimes(summary, yesterday, yesterday)
+	realtime := filepath.Join(config.RealtimeBroadcaster.OutputDir, "old.jsonl")
+	if err := os.WriteFile(realtime, []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+	}
+	os.Chtimes(realtime, yesterday, yesterday)
+
+	expired := filepath.Join(config.LogArchive.OutputDir, "game-"+now.Add(-60*24*time.Hour).Format("2006-01-02")+".tar")
+	if err := os.WriteFile(expired, []byte{}, 0644); err != nil {
+		t.Fatalf("アーカイブの作成に失敗しました: %v", err)
+	}
+
+	service.NewLogArchiver(config).Sweep(now)
+
+	if _, err := os.Stat(filepath.Join(config.LogArchive.OutputDir, "game-"+yesterday.Format("2006-01-02")+".tar")); err != nil {
+		t.Fatalf("アーカイブが作成されていません: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(config.GameLogger.OutputDir, "old.log")); !os.IsNotExist(err) {
+		t.Errorf("アーカイブ済みのログファイルが残っています")
+	}
+	if _, err := os.Stat(filepath.Join(config.GameLogger.OutputDir, "new.log")); err != nil {
+		t.Errorf("最近のログファイルが圧縮されています: %v", err)
+	}
+	if _, err := os.Stat(realtime); err != nil {
+		t.Errorf("配信中のリアルタイムログが圧縮されています: %v", err)
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Errorf("保持期間を超えたアーカイブが削除されていません")
+	}
+
+	count := 0
+	err := util.WalkLogFiles(config.GameLogger.OutputDir, config.LogArchive.OutputDir, util.ArchivePrefixGame, ".log", func(name string, r io.Reader) error {
+		reader, err := gamelog.NewReader(r)
+		if err != nil {
+			return err
+		}
+		events, err := reader.ReadAll()
+		if err != nil {
+			return err
+		}
+		if len(events) != 1 {
+			t.Errorf("イベント数が一致しません: %s %d", name, len(events))
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ログファイルの読み込みに失敗しました: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("読み込んだログファイル数が一致しません: %d", count)
+	}
+
+	err = util.WalkLogFiles(t.TempDir(), config.LogArchive.OutputDir, util.ArchivePrefixJSON, ".json", func(name string, r io.Reader) error {
+		t.Errorf("ゲームログのアーカイブ内のファイルがJSONログとして読み込まれました: %s", name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ログファイルの読み込みに失敗しました: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/service"
+)
+
+func TestGameLoggerAppend(t *testing.T) {
+	config := model.Config{}
+	config.GameLogger.OutputDir = t.TempDir()
+	config.GameLogger.Filename = "{game_id}"
+	logger := service.NewGameLogger(config)
+	path := filepath.Join(config.GameLogger.OutputDir, "game.log")
+
+	logger.TrackStartGame("game", []*model.Agent{{Idx: 1, TeamName: "alpha"}})
+	logger.AppendEvent("game", gamelog.ResultEvent{Day: 1, Villagers: 1, Werewolves: 0, WinSide: model.T_VILLAGER})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("進行中のゲームログが読み込めません: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("フェーズの切り替わり前にゲームログが書き出されています: %q", data)
+	}
+
+	logger.OnGameEvent(model.GameState{ID: "game"}, model.PhaseChangeEvent{IsDaytime: false})
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("進行中のゲームログが読み込めません: %v", err)
+	}
+	if lines := strings.Split(string(data), "\n"); len(lines) != 2 || lines[0] != gamelog.Header() {
+		t.Errorf("進行中のゲームログが書き込まれていません: %q", data)
+	}
+
+	logger.AppendEvent("game", gamelog.ResultEvent{Day: 2, Villagers: 1, Werewolves: 0, WinSide: model.T_VILLAGER})
+	logger.TrackEndGame("game")
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ゲームログの読み込みに失敗しました: %v", err)
+	}
+	if strings.Count(string(data), "\n") != 2 {
+		t.Errorf("ゲームログの行数が一致しません: %q", data)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("一時ファイルが残っています: %v", err)
+	}
+}
+
+func TestJSONLoggerAtomic(t *testing.T) {
+	config := model.Config{}
+	config.JSONLogger.OutputDir = t.TempDir()
+	config.JSONLogger.Filename = "{game_id}"
+	logger := service.NewJSONLogger(config)
+	path := filepath.Join(config.JSONLogger.OutputDir, "game.json")
+
+	agent := &model.Agent{Idx: 1, TeamName: "alpha", OriginalName: "alpha1", GameName: "Agent[01]"}
+	logger.TrackStartGame("game", []*model.Agent{agent})
+	for range 2 {
+		logger.TrackStartRequest("game", *agent, model.Packet{Request: &model.R_TALK})
+		logger.TrackEndRequest("game", *agent, "hello", nil)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("ゲーム終了前にJSONログが作成されています: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); err != nil {
+		t.Errorf("一時ファイルが作成されていません: %v", err)
+	}
+
+	logger.TrackEndGame("game", model.T_VILLAGER)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("JSONログの読み込みに失敗しました: %v", err)
+	}
+	var log struct {
+		GameID  string           `json:"game_id"`
+		WinSide model.Team       `json:"win_side"`
+		Agents  []map[string]any `json:"agents"`
+		Entries []map[string]any `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("JSONログのパースに失敗しました: %v", err)
+	}
+	if log.GameID != "game" || log.WinSide != model.T_VILLAGER || len(log.Agents) != 1 || len(log.Entries) != 2 || log.Entries[1]["response"] != "hello" {
+		t.Errorf("JSONログの内容が一致しません: %s", data)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("一時ファイルが残っています: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"log/slog"
+	"path/filepath"
 	"testing"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/core"
@@ -36,3 +37,60 @@ func TestInitializeMatchOptimizer(t *testing.T) {
 		slog.Info("team", "idx", i, "roles", roleCounts[i])
 	}
 }
+
+func TestRegisteredTeamsMatchOptimizer(t *testing.T) {
+	config, err := model.LoadFromPath("./config/optimize.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Matching.Teams = []string{"alpha", "bravo", "charlie", "delta", "echo"}
+
+	mo, err := core.NewMatchOptimizerFromConfig(*config)
+	if err != nil {
+		t.Fatalf("マッチオプティマイザの初期化に失敗しました: %v", err)
+	}
+	for idx, team := range config.Matching.Teams {
+		if mo.IdxTeamMap[idx] != team {
+			t.Errorf("登録チームが一致しません: idx=%d, expected=%s, actual=%s", idx, team, mo.IdxTeamMap[idx])
+		}
+	}
+
+	config.Matching.Teams = []string{"alpha", "bravo"}
+	if _, err := core.NewMatchOptimizerFromConfig(*config); err == nil {
+		t.Errorf("登録チーム数とチーム数が一致しない場合はエラーになるべきです")
+	}
+}
+
+func TestMatchOptimizerConfigTeams(t *testing.T) {
+	config, err := model.LoadFromPath("./config/optimize.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Matching.OutputPath = filepath.Join(t.TempDir(), "match_optimizer.json")
+	config.Matching.TeamCount = 0
+	config.Matching.Teams = []string{"alpha", "bravo", "charlie", "delta", "echo"}
+	if _, err := core.NewMatchOptimizerFromConfig(*config); err != nil {
+		t.Fatalf("マッチオプティマイザの初期化に失敗しました: %v", err)
+	}
+
+	config.Matching.Teams = []string{"bravo", "alpha", "charlie", "delta", "foxtrot"}
+	mo, err := core.NewMatchOptimizer(*config)
+	if err != nil {
+		t.Fatalf("マッチオプティマイザの読み込みに失敗しました: %v", err)
+	}
+	for idx, team := range config.Matching.Teams {
+		if mo.IdxTeamMap[idx] != team {
+			t.Errorf("設定ファイルの登録チームが優先されていません: idx=%d, expected=%s, actual=%s", idx, team, mo.IdxTeamMap[idx])
+		}
+	}
+
+	config.Matching.Teams = []string{"charlie", "alpha", "charlie", "delta", "foxtrot"}
+	if _, err := core.NewMatchOptimizer(*config); err == nil {
+		t.Errorf("登録チームが別のインデックスに割り当てられている場合はエラーになるべきです")
+	}
+
+	config.Matching.Teams = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot"}
+	if _, err := core.NewMatchOptimizer(*config); err == nil {
+		t.Errorf("登録チーム数が保存されたチーム数と一致しない場合はエラーになるべきです")
+	}
+}
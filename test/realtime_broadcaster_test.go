@@ -0,0 +1,267 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/service"
+)
+
+func TestRealtimeSubscription(t *testing.T) {
+	config := model.Config{}
+	config.RealtimeBroadcaster.Enable = true
+	config.RealtimeBroadcaster.OutputDir = t.TempDir()
+	config.RealtimeBroadcaster.Filename = "{game_id}"
+	rb := service.NewRealtimeBroadcaster(config)
+	if rb == nil {
+		t.Fatalf("リアルタイムブロードキャスターの初期化に失敗しました")
+	}
+
+	agents := []*model.Agent{
+		{Idx: 1, TeamName: "alpha", OriginalName: "alpha1", GameName: "Agent[01]", Role: model.R_SEER},
+		{Idx: 2, TeamName: "beta", OriginalName: "beta1", GameName: "Agent[02]", Role: model.R_WEREWOLF},
+	}
+	state := model.GameState{
+		ID:        "game",
+		Agents:    agents,
+		StatusMap: map[model.Agent]model.Status{*agents[0]: model.S_ALIVE, *agents[1]: model.S_ALIVE},
+	}
+	talk := func(text string) {
+		rb.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: *agents[0], Text: text}})
+	}
+
+	if _, err := rb.Subscribe("missing", service.RV_AUDIENCE, 0); err == nil {
+		t.Errorf("存在しないゲームでエラーが発生しませんでした")
+	}
+
+	rb.OnGameEvent(state, model.GameStartEvent{})
+	talk("first")
+	subscription, err := rb.Subscribe("game", service.RV_AUDIENCE, 1)
+	if err != nil {
+		t.Fatalf("購読に失敗しました: %v", err)
+	}
+	if len(subscription.Backlog) != 1 || subscription.Backlog[0].Idx != 2 {
+		t.Fatalf("再開位置以降のパケットが一致しません: %+v", subscription.Backlog)
+	}
+
+	talk("second")
+	select {
+	case packet := <-subscription.Packets:
+		var broadcast model.BroadcastPacket
+		if err := json.Unmarshal(packet.Data, &broadcast); err != nil {
+			t.Fatalf("パケットのパースに失敗しました: %v", err)
+		}
+		if packet.Idx != 3 || broadcast.Message == nil || *broadcast.Message != "second" {
+			t.Errorf("配信されたパケットが一致しません: %+v", broadcast)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("パケットが配信されませんでした")
+	}
+
+	rb.OnGameEvent(state, model.GameEndEvent{WinSide: model.T_VILLAGER})
+	for range subscription.Packets {
+	}
+
+	ended, err := rb.Subscribe("game", service.RV_AUDIENCE, 2)
+	if err != nil {
+		t.Fatalf("終了したゲームの購読に失敗しました: %v", err)
+	}
+	if len(ended.Backlog) != 2 || ended.Backlog[0].Idx != 3 || ended.Backlog[1].Idx != 4 {
+		t.Errorf("終了したゲームのパケットが一致しません: %+v", ended.Backlog)
+	}
+	var resumed model.BroadcastPacket
+	if err := json.Unmarshal(ended.Backlog[0].Data, &resumed); err != nil {
+		t.Fatalf("パケットのパースに失敗しました: %v", err)
+	}
+	if !resumed.Snapshot || len(resumed.Agents) != 2 {
+		t.Errorf("再開時の最初のパケットが完全な状態ではありません: %+v", resumed)
+	}
+	if reconstructed, err := rb.Reconstruct("game", service.RV_AUDIENCE, 0); err != nil || !reconstructed.Snapshot || reconstructed.Idx != 4 {
+		t.Errorf("最新の状態を復元できません: %+v %v", reconstructed, err)
+	}
+	if _, open := <-ended.Packets; open {
+		t.Errorf("終了したゲームの購読が閉じられていません")
+	}
+
+	restarted := service.NewRealtimeBroadcaster(config)
+	games, total := restarted.Games(1, 10)
+	if total != 1 || len(games) != 1 {
+		t.Fatalf("ゲームインデックスの件数が一致しません: %d", total)
+	}
+	if games[0].ID != "game" || games[0].WinSide != model.T_VILLAGER || games[0].EndedAt == nil || len(games[0].Teams) != 2 {
+		t.Errorf("ゲームインデックスの内容が一致しません: %+v", games[0])
+	}
+	if _, err := restarted.Subscribe("game", service.RV_AUDIENCE, 0); err != nil {
+		t.Errorf("再起動後に終了したゲームを購読できません: %v", err)
+	}
+	if games, _ := restarted.Games(2, 10); len(games) != 0 {
+		t.Errorf("範囲外のページでゲームが返されました: %+v", games)
+	}
+}
+
+func TestRealtimeBlindDelay(t *testing.T) {
+	config := model.Config{}
+	config.RealtimeBroadcaster.Enable = true
+	config.RealtimeBroadcaster.OutputDir = t.TempDir()
+	config.RealtimeBroadcaster.Filename = "{game_id}"
+	config.RealtimeBroadcaster.Delay = 100 * time.Millisecond
+	config.RealtimeBroadcaster.Blind = true
+	config.RealtimeBroadcaster.CommentatorDir = t.TempDir()
+	rb := service.NewRealtimeBroadcaster(config)
+	if rb == nil {
+		t.Fatalf("リアルタイムブロードキャスターの初期化に失敗しました")
+	}
+
+	agents := []*model.Agent{
+		{Idx: 1, TeamName: "alpha", OriginalName: "alpha1", GameName: "Agent[01]", Role: model.R_SEER},
+		{Idx: 2, TeamName: "beta", OriginalName: "beta1", GameName: "Agent[02]", Role: model.R_WEREWOLF},
+	}
+	state := model.GameState{
+		ID:        "game",
+		Agents:    agents,
+		StatusMap: map[model.Agent]model.Status{*agents[0]: model.S_ALIVE, *agents[1]: model.S_ALIVE},
+	}
+	rb.OnGameEvent(state, model.GameStartEvent{})
+	rb.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: *agents[0], Text: "talk"}})
+	rb.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: *agents[1], Text: "whisper"}, Whisper: true})
+	rb.OnGameEvent(state, model.DivineEvent{Judge: model.Judge{Agent: *agents[0], Target: *agents[1], Result: model.S_WEREWOLF}})
+
+	commentator, err := rb.Subscribe("game", service.RV_COMMENTATOR, 0)
+	if err != nil {
+		t.Fatalf("解説者ストリームの購読に失敗しました: %v", err)
+	}
+	if len(commentator.Backlog) != 4 {
+		t.Errorf("解説者ストリームのパケット数が一致しません: %d", len(commentator.Backlog))
+	}
+	for i, packet := range commentator.Backlog {
+		if packet.Idx != i+1 {
+			t.Errorf("解説者ストリームのインデックスが連番ではありません: %d", packet.Idx)
+		}
+	}
+	audience, err := rb.Subscribe("game", service.RV_AUDIENCE, 0)
+	if err != nil {
+		t.Fatalf("観客ストリームの購読に失敗しました: %v", err)
+	}
+	if len(audience.Backlog) != 0 {
+		t.Errorf("遅延時間の経過前にパケットが配信されました: %d", len(audience.Backlog))
+	}
+
+	rb.OnGameEvent(state, model.GameEndEvent{WinSide: model.T_WEREWOLF})
+	packets := []model.BroadcastPacket{}
+	for packet := range audience.Packets {
+		var broadcast model.BroadcastPacket
+		if err := json.Unmarshal(packet.Data, &broadcast); err != nil {
+			t.Fatalf("パケットのパースに失敗しました: %v", err)
+		}
+		packets = append(packets, broadcast)
+	}
+	if len(packets) != 3 {
+		t.Fatalf("観客ストリームのパケット数が一致しません: %d", len(packets))
+	}
+	for i, packet := range packets {
+		if packet.Idx != i+1 {
+			t.Errorf("観客ストリームのインデックスが連番ではありません: %d", packet.Idx)
+		}
+	}
+	if !packets[0].Snapshot || packets[1].Snapshot || !packets[2].Snapshot {
+		t.Errorf("スナップショットの位置が一致しません: %+v", packets)
+	}
+	if packets[0].Agents[0].Role != "" || len(packets[1].AgentChanges) != 0 {
+		t.Errorf("役職が隠されていません: %+v", packets[1])
+	}
+	if packets[2].Agents[1].Role != "WEREWOLF" {
+		t.Errorf("ゲーム終了時に役職が公開されていません: %+v", packets[2].Agents)
+	}
+	if packets[2].Event != model.BE_END || packets[2].Result == nil || *packets[2].Result != model.T_WEREWOLF {
+		t.Errorf("終了パケットの勝利チームが一致しません: %+v", packets[2])
+	}
+
+	restarted := service.NewRealtimeBroadcaster(config)
+	ended, err := restarted.Subscribe("game", service.RV_COMMENTATOR, 0)
+	if err != nil {
+		t.Fatalf("終了したゲームの解説者ストリームを購読できません: %v", err)
+	}
+	if len(ended.Backlog) != 5 {
+		t.Errorf("保存された解説者ストリームのパケット数が一致しません: %d", len(ended.Backlog))
+	}
+	if divine, err := restarted.Reconstruct("game", service.RV_COMMENTATOR, ended.Backlog[3].Idx); err != nil || divine.Event != model.BE_DIVINE {
+		t.Errorf("保存された解説者ストリームから占い結果を復元できません: %+v %v", divine, err)
+	}
+}
+
+func TestRealtimeFlush(t *testing.T) {
+	config := model.Config{}
+	config.RealtimeBroadcaster.Enable = true
+	config.RealtimeBroadcaster.OutputDir = t.TempDir()
+	config.RealtimeBroadcaster.Filename = "{game_id}"
+	rb := service.NewRealtimeBroadcaster(config)
+	if rb == nil {
+		t.Fatalf("リアルタイムブロードキャスターの初期化に失敗しました")
+	}
+
+	agents := []*model.Agent{{Idx: 1, TeamName: "alpha", OriginalName: "alpha1", GameName: "Agent[01]", Role: model.R_SEER}}
+	state := model.GameState{ID: "game", Agents: agents, StatusMap: map[model.Agent]model.Status{*agents[0]: model.S_ALIVE}}
+	rb.OnGameEvent(state, model.GameStartEvent{})
+	rb.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: *agents[0], Text: "talk"}})
+
+	path := filepath.Join(config.RealtimeBroadcaster.OutputDir, "game.jsonl")
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		data, err := os.ReadFile(path)
+		if err == nil && strings.Count(string(data), "\n") == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("配信中のゲームファイルが書き出されませんでした: %q %v", data, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	rb.OnGameEvent(state, model.GameEndEvent{WinSide: model.T_VILLAGER})
+}
+
+func TestRealtimeDelayBacklog(t *testing.T) {
+	config := model.Config{}
+	config.RealtimeBroadcaster.Enable = true
+	config.RealtimeBroadcaster.OutputDir = t.TempDir()
+	config.RealtimeBroadcaster.Filename = "{game_id}"
+	config.RealtimeBroadcaster.Delay = 500 * time.Millisecond
+	rb := service.NewRealtimeBroadcaster(config)
+	if rb == nil {
+		t.Fatalf("リアルタイムブロードキャスターの初期化に失敗しました")
+	}
+
+	agents := []*model.Agent{{Idx: 1, TeamName: "alpha", OriginalName: "alpha1", GameName: "Agent[01]", Role: model.R_SEER}}
+	state := model.GameState{ID: "game", Agents: agents, StatusMap: map[model.Agent]model.Status{*agents[0]: model.S_ALIVE}}
+	started := time.Now()
+	rb.OnGameEvent(state, model.GameStartEvent{})
+	for range 2000 {
+		rb.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: *agents[0], Text: "talk"}})
+	}
+	rb.OnGameEvent(state, model.GameEndEvent{WinSide: model.T_VILLAGER})
+	if elapsed := time.Since(started); elapsed >= config.RealtimeBroadcaster.Delay {
+		t.Errorf("遅延配信の待ちでゲームの進行が止まりました: %v", elapsed)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if games, _ := rb.Games(1, 10); len(games) == 1 && games[0].EndedAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("遅延配信が終了しませんでした")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	data, err := os.ReadFile(filepath.Join(config.RealtimeBroadcaster.OutputDir, "game.jsonl"))
+	if err != nil {
+		t.Fatalf("ゲームファイルの読み込みに失敗しました: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n") + 1; lines != 2002 {
+		t.Errorf("遅延配信されたパケット数が一致しません: %d", lines)
+	}
+}
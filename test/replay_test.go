@@ -0,0 +1,49 @@
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestReplay5Game(t *testing.T) {
+	config, err := model.LoadFromPath("./config/full5.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.JSONLogger.OutputDir = t.TempDir()
+	config.GameLogger.OutputDir = t.TempDir()
+	config.RealtimeBroadcaster.Enable = false
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_VOTE:   handleTarget,
+		model.R_DIVINE: handleTarget,
+		model.R_GUARD:  handleTarget,
+		model.R_TALK: func(tc TestClient) (string, error) {
+			return "Hello, World!", nil
+		},
+		model.R_WHISPER: func(tc TestClient) (string, error) {
+			return "Hello, World!", nil
+		},
+		model.R_ATTACK: handleTarget,
+	}
+	executeSelfMatchGame(t, config, handlers)
+
+	jsonPaths, err := filepath.Glob(filepath.Join(config.JSONLogger.OutputDir, "*.json"))
+	if err != nil || len(jsonPaths) != 1 {
+		t.Fatalf("JSONログが見つかりません: %v", jsonPaths)
+	}
+	id := strings.TrimSuffix(filepath.Base(jsonPaths[0]), ".json")
+	logPath := filepath.Join(config.GameLogger.OutputDir, id+".log")
+
+	replayConfig, err := model.LoadFromPath("./config/full5.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	if err := core.Replay(*replayConfig, jsonPaths[0], logPath); err != nil {
+		t.Fatalf("リプレイ結果が一致しません: %v", err)
+	}
+}
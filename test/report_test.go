@@ -0,0 +1,263 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/gamelog"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestAnalyzerReport(t *testing.T) {
+	config := model.Config{}
+	config.GameLogger.Enable = true
+	config.GameLogger.OutputDir = t.TempDir()
+	config.Matching.OutputPath = filepath.Join(t.TempDir(), "missing.json")
+
+	games := []model.Team{model.T_VILLAGER, model.T_WEREWOLF}
+	for i, winSide := range games {
+		lines := []string{
+			gamelog.Header(),
+			gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 1, Role: model.R_SEER, Status: model.S_ALIVE, OriginalName: "alpha1", GameName: "Agent[01]"}),
+			gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 2, Role: model.R_WEREWOLF, Status: model.S_ALIVE, OriginalName: "beta1", GameName: "Agent[02]"}),
+			gamelog.Format(gamelog.ResultEvent{Day: 1, Villagers: 1, Werewolves: 1, WinSide: winSide}),
+		}
+		path := filepath.Join(config.GameLogger.OutputDir, string(rune('a'+i))+".log")
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+		}
+	}
+
+	report := core.Analyzer(config)
+	if len(report.Teams) != 2 {
+		t.Fatalf("チーム数が一致しません: %d", len(report.Teams))
+	}
+	alpha := report.Teams[0]
+	if alpha.Team != "alpha" || alpha.Total.Games != 2 || alpha.Total.Win != 1 || alpha.Total.WinRate != 0.5 {
+		t.Errorf("統計データが一致しません: %+v", alpha)
+	}
+	if alpha.Total.WinRateLower >= 0.5 || alpha.Total.WinRateUpper <= 0.5 {
+		t.Errorf("信頼区間が勝率を含んでいません: %+v", alpha.Total)
+	}
+	if len(report.Comparisons) != 1 || report.Comparisons[0].Significant {
+		t.Errorf("チーム間の比較が一致しません: %+v", report.Comparisons)
+	}
+
+	dir := t.TempDir()
+	for _, format := range []string{core.RF_CSV, core.RF_JSON, core.RF_HTML} {
+		if err := report.Export(format, filepath.Join(dir, "report."+format)); err != nil {
+			t.Fatalf("レポートの出力に失敗しました: %s %v", format, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatalf("レポートの読み込みに失敗しました: %v", err)
+	}
+	var exported core.Report
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("レポートのパースに失敗しました: %v", err)
+	}
+	if len(exported.Teams) != 2 || exported.Teams[1].Roles[0].Role != "WEREWOLF" {
+		t.Errorf("出力したレポートが一致しません: %+v", exported.Teams)
+	}
+	if err := report.Export("xml", filepath.Join(dir, "report.xml")); err == nil {
+		t.Errorf("不明なレポート形式でエラーが発生しませんでした")
+	}
+}
+
+func TestAnalyzerComparisons(t *testing.T) {
+	config := model.Config{}
+	config.GameLogger.Enable = true
+	config.GameLogger.OutputDir = t.TempDir()
+	config.Matching.OutputPath = filepath.Join(t.TempDir(), "missing.json")
+
+	count := 0
+	for _, winner := range []string{"alpha", "gamma"} {
+		for i := range 20 {
+			villager, werewolf, winSide := winner, "beta", model.T_VILLAGER
+			if i%2 == 1 {
+				villager, werewolf, winSide = "beta", winner, model.T_WEREWOLF
+			}
+			lines := []string{
+				gamelog.Header(),
+				gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 1, Role: model.R_SEER, Status: model.S_ALIVE, OriginalName: villager + "1", GameName: "Agent[01]"}),
+				gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 2, Role: model.R_WEREWOLF, Status: model.S_ALIVE, OriginalName: werewolf + "1", GameName: "Agent[02]"}),
+				gamelog.Format(gamelog.ResultEvent{Day: 1, Villagers: 1, Werewolves: 1, WinSide: winSide}),
+			}
+			count++
+			path := filepath.Join(config.GameLogger.OutputDir, fmt.Sprintf("%03d.log", count))
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+				t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+			}
+		}
+	}
+
+	report := core.Analyzer(config)
+	if len(report.Comparisons) != 3 {
+		t.Fatalf("チーム間の比較数が一致しません: %d", len(report.Comparisons))
+	}
+	for _, comparison := range report.Comparisons {
+		switch comparison.TeamA + "-" + comparison.TeamB {
+		case "alpha-beta":
+			if !comparison.Significant || comparison.WinRateA != 1 || comparison.AdjustedPValue != min(1, 3*comparison.PValue) {
+				t.Errorf("補正したp値が一致しません: %+v", comparison)
+			}
+		case "alpha-gamma":
+			if comparison.Significant || comparison.AdjustedPValue != 1 {
+				t.Errorf("同じ勝率のチーム間に有意差があります: %+v", comparison)
+			}
+		}
+	}
+}
+
+func TestAnalyzerLatency(t *testing.T) {
+	config := model.Config{}
+	config.JSONLogger.Enable = true
+	config.JSONLogger.OutputDir = t.TempDir()
+	config.Matching.OutputPath = filepath.Join(t.TempDir(), "missing.json")
+
+	entries := []map[string]any{}
+	for i, duration := range []int64{100, 300, 200} {
+		entries = append(entries, map[string]any{
+			"agent":              "Agent[01]",
+			"request":            `{"request":"TALK","info":{"day":1}}`,
+			"request_timestamp":  int64(i * 1000),
+			"response_timestamp": int64(i*1000) + duration,
+		})
+	}
+	entries = append(entries, map[string]any{
+		"agent":              "Agent[01]",
+		"request":            `{"request":"VOTE","info":{"day":2}}`,
+		"request_timestamp":  int64(5000),
+		"response_timestamp": int64(9000),
+		"error":              model.ErrResponseTimeout.Error(),
+	})
+	data, err := json.Marshal(map[string]any{
+		"game_id": "game",
+		"agents": []map[string]any{
+			{"idx": 1, "team": "alpha", "name": "alpha1", "game_name": "Agent[01]"},
+		},
+		"entries": entries,
+	})
+	if err != nil {
+		t.Fatalf("ログのJSON化に失敗しました: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(config.JSONLogger.OutputDir, "game.json"), data, 0644); err != nil {
+		t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+	}
+	legacy, err := json.Marshal(map[string]any{
+		"game_id": "legacy",
+		"agents": []map[string]any{
+			{"idx": 1, "team": "beta", "name": "beta1"},
+		},
+		"entries": []map[string]any{
+			{"agent": "Agent[01]", "request": `{"request":"INITIALIZE"}`, "response_timestamp": int64(0)},
+			{"agent": "Agent[01]", "request": `{"request":"TALK","info":{"day":1}}`, "request_timestamp": int64(1000), "response_timestamp": int64(1400)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ログのJSON化に失敗しました: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(config.JSONLogger.OutputDir, "legacy.json"), legacy, 0644); err != nil {
+		t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+	}
+
+	report := core.Analyzer(config)
+	if len(report.Latency) != 2 {
+		t.Fatalf("チーム数が一致しません: %d", len(report.Latency))
+	}
+	if beta := report.Latency[1]; beta.Team != "beta" || len(beta.Requests) != 2 || beta.Requests[1].Request != "TALK" || beta.Requests[1].Max != 400 {
+		t.Errorf("ゲーム内名が記録されていないログの応答時間が集計されていません: %+v", beta)
+	}
+	latency := report.Latency[0]
+	if latency.Team != "alpha" || latency.Timeouts != 1 || latency.NameFallbacks != 1 {
+		t.Errorf("タイムアウト数が一致しません: %+v", latency)
+	}
+	talk := latency.Requests[0]
+	if talk.Request != "TALK" || talk.Count != 3 || talk.Mean != 200 || talk.P50 != 200 || talk.Max != 300 {
+		t.Errorf("応答時間の統計データが一致しません: %+v", talk)
+	}
+	if len(latency.Slowest) != 4 || latency.Slowest[0].Request != "VOTE" || latency.Slowest[0].Day != 2 {
+		t.Errorf("最も遅いリクエストが一致しません: %+v", latency.Slowest)
+	}
+
+	dir := t.TempDir()
+	if err := report.Export(core.RF_CSV, filepath.Join(dir, "report.csv")); err != nil {
+		t.Fatalf("レポートの出力に失敗しました: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report_latency.csv")); err != nil {
+		t.Errorf("応答時間のレポートが出力されていません: %v", err)
+	}
+}
+
+func TestTalkAnalyzer(t *testing.T) {
+	config := model.Config{}
+	config.GameLogger.Enable = true
+	config.GameLogger.OutputDir = t.TempDir()
+
+	lines := []string{
+		gamelog.Header(),
+		gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 1, Role: model.R_SEER, Status: model.S_ALIVE, OriginalName: "alpha1", GameName: "Agent[01]"}),
+		gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 2, Role: model.R_WEREWOLF, Status: model.S_ALIVE, OriginalName: "beta1", GameName: "Agent[02]"}),
+		gamelog.Format(gamelog.TalkEvent{Day: 1, Idx: 0, Turn: 0, AgentIdx: 1, Text: "私は占い師です。@Agent[02]は人狼でした"}),
+		gamelog.Format(gamelog.TalkEvent{Day: 1, Idx: 1, Turn: 0, AgentIdx: 2, Text: model.T_SKIP}),
+		gamelog.Format(gamelog.TalkEvent{Day: 1, Idx: 2, Turn: 1, AgentIdx: 1, Text: model.T_OVER}),
+		gamelog.Format(gamelog.TalkEvent{Day: 1, Idx: 0, Turn: 0, AgentIdx: 2, Text: "今日は誰を襲う?", Whisper: true}),
+	}
+	if err := os.WriteFile(filepath.Join(config.GameLogger.OutputDir, "game.log"), []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+	}
+
+	report := core.TalkAnalyzer(config)
+	if len(report.Teams) != 2 {
+		t.Fatalf("チーム数が一致しません: %d", len(report.Teams))
+	}
+	alpha := report.Teams[0].Total
+	if alpha.Talks != 2 || alpha.Overs != 1 || alpha.OverRate != 0.5 || alpha.Mentions != 1 || alpha.Claims["SEER"] != 1 || alpha.Claims["WEREWOLF"] != 0 {
+		t.Errorf("発話の統計データが一致しません: %+v", alpha)
+	}
+	if alpha.LengthMax != int64(len([]rune("私は占い師です。@Agent[02]は人狼でした"))) || alpha.Vocabulary == 0 {
+		t.Errorf("発話長または語彙数が一致しません: %+v", alpha)
+	}
+	beta := report.Teams[1].Total
+	if beta.Talks != 1 || beta.Whispers != 1 || beta.Skips != 1 || beta.SkipRate != 0.5 {
+		t.Errorf("発話の統計データが一致しません: %+v", beta)
+	}
+
+	dir := t.TempDir()
+	for _, format := range []string{core.RF_CSV, core.RF_JSON, core.RF_HTML} {
+		if err := report.Export(format, filepath.Join(dir, "talk_report."+format)); err != nil {
+			t.Fatalf("レポートの出力に失敗しました: %s %v", format, err)
+		}
+	}
+	html, err := os.ReadFile(filepath.Join(dir, "talk_report.html"))
+	if err != nil || !strings.Contains(string(html), "tr.total td") {
+		t.Errorf("HTMLレポートにスタイルが含まれていません: %v", err)
+	}
+}
+
+func TestTalkAnalyzerMentions(t *testing.T) {
+	config := model.Config{}
+	config.GameLogger.Enable = true
+	config.GameLogger.OutputDir = t.TempDir()
+
+	lines := []string{
+		gamelog.Header(),
+		gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 1, Role: model.R_SEER, Status: model.S_ALIVE, OriginalName: "alpha1", GameName: "Mina"}),
+		gamelog.Format(gamelog.StatusEvent{Day: 0, Idx: 2, Role: model.R_WEREWOLF, Status: model.S_ALIVE, OriginalName: "beta1", GameName: "Minato"}),
+		gamelog.Format(gamelog.TalkEvent{Day: 1, Idx: 0, Turn: 0, AgentIdx: 1, Text: "@Minato は人狼です。@Minaは占い師、@Minax は誰?"}),
+	}
+	if err := os.WriteFile(filepath.Join(config.GameLogger.OutputDir, "game.log"), []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatalf("ログファイルの作成に失敗しました: %v", err)
+	}
+
+	report := core.TalkAnalyzer(config)
+	if len(report.Teams) == 0 || report.Teams[0].Total.Mentions != 2 {
+		t.Errorf("名前全体が一致するメンションの数が一致しません: %+v", report.Teams)
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestGameSummary(t *testing.T) {
+	agents := []*model.Agent{
+		{Idx: 1, TeamName: "alpha", GameName: "Agent[01]", Role: model.R_SEER},
+		{Idx: 2, TeamName: "beta", GameName: "Agent[02]", Role: model.R_WEREWOLF},
+		{Idx: 3, TeamName: "gamma", GameName: "Agent[03]", Role: model.R_MEDIUM},
+		{Idx: 4, TeamName: "delta", GameName: "Agent[04]", Role: model.R_BODYGUARD},
+	}
+	statusMap := map[model.Agent]model.Status{}
+	for _, agent := range agents {
+		statusMap[*agent] = model.S_ALIVE
+	}
+	state := func(day int) model.GameState {
+		return model.GameState{ID: "game", Day: day, Agents: agents, StatusMap: statusMap}
+	}
+
+	summary := model.NewGameSummary(agents)
+	summary.Record(state(0), model.DivineEvent{Judge: model.Judge{Agent: *agents[0], Target: *agents[1], Result: model.S_WEREWOLF}})
+	summary.Record(state(1), model.TalkEvent{Talk: model.Talk{Agent: *agents[0], Text: "hello"}})
+	summary.Record(state(1), model.TalkEvent{Talk: model.Talk{Agent: *agents[0], Text: model.T_OVER}})
+	summary.Record(state(1), model.TalkEvent{Talk: model.Talk{Agent: *agents[1], Text: "hi"}, Whisper: true})
+	summary.Record(state(1), model.VoteEvent{Vote: model.Vote{Agent: *agents[0], Target: *agents[3]}})
+	statusMap[*agents[3]] = model.S_DEAD
+	summary.Record(state(1), model.ExecutionEvent{Executed: agents[3]})
+	summary.Record(state(1), model.AttackEvent{Attacked: agents[0], Guarded: true})
+	dailyInitialize := func(agent *model.Agent, result *model.Judge) model.RequestStartEvent {
+		return model.RequestStartEvent{Agent: *agent, Packet: model.Packet{Request: &model.R_DAILY_INITIALIZE, Info: &model.Info{Day: 2, MediumResult: result}}}
+	}
+	summary.Record(state(2), dailyInitialize(agents[0], nil))
+	summary.Record(state(2), dailyInitialize(agents[2], &model.Judge{Day: 1, Agent: *agents[3], Target: *agents[3], Result: agents[3].Role.Species}))
+	statusMap[*agents[1]] = model.S_DEAD
+	summary.Record(state(2), model.ExecutionEvent{Executed: agents[1]})
+	summary.Record(state(3), model.GameEndEvent{WinSide: model.T_VILLAGER})
+
+	if summary.WinSide != model.T_VILLAGER || summary.Days != 3 {
+		t.Errorf("勝利チームまたは日数が一致しません: %+v", summary)
+	}
+	if len(summary.Timeline) != 3 || summary.Timeline[1].Type != model.GE_ATTACK || !summary.Timeline[1].Guarded {
+		t.Errorf("タイムラインが一致しません: %+v", summary.Timeline)
+	}
+	seer, werewolf, medium, bodyguard := summary.Agents[0], summary.Agents[1], summary.Agents[2], summary.Agents[3]
+	if seer.Talks != 1 || werewolf.Whispers != 1 || len(seer.Votes) != 1 || seer.Votes[0].Target != 4 {
+		t.Errorf("発話数または投票が一致しません: %+v", seer)
+	}
+	if len(seer.DivineResults) != 1 || seer.DivineResults[0].Result != model.S_WEREWOLF {
+		t.Errorf("占い結果が一致しません: %+v", seer.DivineResults)
+	}
+	if len(medium.MediumResults) != 1 || medium.MediumResults[0].Day != 1 || medium.MediumResults[0].Target != 4 || medium.MediumResults[0].Result != model.S_HUMAN {
+		t.Errorf("霊能結果が一致しません: %+v", medium.MediumResults)
+	}
+	if bodyguard.Alive || bodyguard.SurvivalDays != 1 || werewolf.SurvivalDays != 2 || !seer.Alive || seer.SurvivalDays != 3 {
+		t.Errorf("生存日数が一致しません: %+v", summary.Agents)
+	}
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/gorilla/websocket"
+)
+
+func TestTeamQuota(t *testing.T) {
+	config := model.Config{}
+	config.Server.Quota.MaxConnectionsPerTeam = 2
+	config.Server.Quota.MaxGamesPerHour = 2
+	tq := core.NewTeamQuota(config)
+
+	tq.AddConnection("alpha")
+	if err := tq.Check("alpha"); err != nil {
+		t.Errorf("上限未満の接続が拒否されました: %v", err)
+	}
+	tq.AddConnection("alpha")
+	if err := tq.Check("alpha"); err == nil {
+		t.Errorf("接続数の上限を超えた接続が許可されました")
+	}
+	if err := tq.Check("beta"); err != nil {
+		t.Errorf("他のチームの接続が拒否されました: %v", err)
+	}
+	tq.RemoveConnection("alpha")
+	if err := tq.Check("alpha"); err != nil {
+		t.Errorf("切断後の接続が拒否されました: %v", err)
+	}
+
+	tq.RecordGame("alpha")
+	tq.RecordGame("alpha")
+	if err := tq.Check("alpha"); err == nil || !strings.Contains(err.Error(), "hourly") {
+		t.Errorf("1時間あたりのゲーム数の上限を超えた接続が許可されました: %v", err)
+	}
+	status := tq.Status()["alpha"]
+	if status.Connections != 1 || status.GamesLastHour != 2 || status.GamesToday != 2 {
+		t.Errorf("チームの集計状況が一致しません: %+v", status)
+	}
+
+	config.Server.Quota.MaxGamesPerHour = 0
+	config.Server.Quota.MaxGamesPerDay = 1
+	daily := core.NewTeamQuota(config)
+	daily.RecordGame("alpha")
+	if err := daily.Check("alpha"); err == nil || !strings.Contains(err.Error(), "daily") {
+		t.Errorf("1日あたりのゲーム数の上限を超えた接続が許可されました: %v", err)
+	}
+}
+
+func TestWaitingRoomRemoveClosedConnections(t *testing.T) {
+	conns := make(chan *websocket.Conn, 2)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}))
+	defer server.Close()
+
+	config := model.Config{}
+	config.Game.AgentCount = 5
+	wr := core.NewWaitingRoom(config)
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	clients := []*websocket.Conn{}
+	for range 2 {
+		client, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("接続に失敗しました: %v", err)
+		}
+		defer client.Close()
+		clients = append(clients, client)
+		wr.AddConnection("alpha", model.Connection{TeamName: "alpha", Conn: <-conns, ConnectedAt: time.Now()})
+	}
+	go func() {
+		for {
+			if _, _, err := clients[1].ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if removed := wr.RemoveClosedConnections(); len(removed) != 0 {
+		t.Fatalf("接続中のクライアントが削除されました: %d", len(removed))
+	}
+	clients[0].Close()
+	removed := []model.Connection{}
+	for deadline := time.Now().Add(3 * time.Second); len(removed) == 0 && time.Now().Before(deadline); {
+		time.Sleep(100 * time.Millisecond)
+		removed = append(removed, wr.RemoveClosedConnections()...)
+	}
+	if len(removed) != 1 || removed[0].TeamName != "alpha" {
+		t.Errorf("切断されたクライアントが削除されませんでした: %d", len(removed))
+	}
+	if removed := wr.RemoveClosedConnections(); len(removed) != 0 {
+		t.Errorf("接続中のクライアントが削除されました: %d", len(removed))
+	}
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/service"
+)
+
+func TestTTSEngine(t *testing.T) {
+	stub, err := service.NewTTSEngine(model.TTSBroadcasterConfig{Engine: service.TE_STUB})
+	if err != nil {
+		t.Fatalf("スタブエンジンの作成に失敗しました: %v", err)
+	}
+	wav, err := stub.Synthesize(context.Background(), "こんにちは、よろしくお願いします", 1)
+	if err != nil {
+		t.Fatalf("スタブエンジンの音声合成に失敗しました: %v", err)
+	}
+	if string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		t.Fatalf("WAVヘッダが不正です: %q", wav[:12])
+	}
+	if size := binary.LittleEndian.Uint32(wav[40:44]); int(size) != len(wav)-44 || size == 0 {
+		t.Errorf("WAVのデータサイズが一致しません: %d", size)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text    string `json:"text"`
+			Speaker int    `json:"speaker"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Text != "hello" || body.Speaker != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(wav)
+	}))
+	defer server.Close()
+	engine, err := service.NewTTSEngine(model.TTSBroadcasterConfig{Engine: service.TE_HTTP, Host: server.URL})
+	if err != nil {
+		t.Fatalf("HTTPエンジンの作成に失敗しました: %v", err)
+	}
+	data, err := engine.Synthesize(context.Background(), "hello", 3)
+	if err != nil || len(data) != len(wav) {
+		t.Errorf("HTTPエンジンの音声合成結果が一致しません: %d %v", len(data), err)
+	}
+
+	if _, err := service.NewTTSEngine(model.TTSBroadcasterConfig{Engine: service.TE_COMMAND}); err == nil {
+		t.Errorf("コマンドが未指定でエラーが発生しませんでした")
+	}
+	if _, err := service.NewTTSEngine(model.TTSBroadcasterConfig{Engine: "unknown"}); err == nil {
+		t.Errorf("不明なエンジンでエラーが発生しませんでした")
+	}
+}
+
+func TestTTSCache(t *testing.T) {
+	config := newTestTTSConfig(t)
+	config.TTSBroadcaster.CacheDir = t.TempDir()
+	tts := service.NewTTSBroadcaster(config)
+	if tts == nil {
+		t.Fatalf("TTSブロードキャスターの初期化に失敗しました")
+	}
+	tts.Start()
+
+	agent := model.Agent{Idx: 1, Profile: &model.Profile{VoiceID: 3}}
+	for _, id := range []string{"game1", "game2"} {
+		state := model.GameState{ID: id}
+		tts.OnGameEvent(state, model.GameStartEvent{})
+		tts.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: agent, Text: "hello"}})
+	}
+
+	entries, err := os.ReadDir(config.TTSBroadcaster.CacheDir)
+	if err != nil {
+		t.Fatalf("キャッシュディレクトリの読み込みに失敗しました: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("キャッシュの件数が一致しません: %d", len(entries))
+	}
+	playlist, err := os.ReadFile(filepath.Join(config.TTSBroadcaster.SegmentDir, "game2", "playlist.m3u8"))
+	if err != nil {
+		t.Fatalf("プレイリストの読み込みに失敗しました: %v", err)
+	}
+	if strings.Count(string(playlist), "#EXTINF") != 2 {
+		t.Errorf("キャッシュから追加されたセグメント数が一致しません: %s", playlist)
+	}
+
+	config.TTSBroadcaster.ConvertArgs = []string{"-ar", "48000"}
+	converted := service.NewTTSBroadcaster(config)
+	if converted == nil {
+		t.Fatalf("TTSブロードキャスターの初期化に失敗しました")
+	}
+	converted.Start()
+	state := model.GameState{ID: "game3"}
+	converted.OnGameEvent(state, model.GameStartEvent{})
+	converted.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: agent, Text: "hello"}})
+	entries, err = os.ReadDir(config.TTSBroadcaster.CacheDir)
+	if err != nil {
+		t.Fatalf("キャッシュディレクトリの読み込みに失敗しました: %v", err)
+	}
+	if len(entries) != 6 {
+		t.Errorf("変換の設定を変更した後のキャッシュの件数が一致しません: %d", len(entries))
+	}
+}
+
+func TestTTSSubtitles(t *testing.T) {
+	config := newTestTTSConfig(t)
+	tts := service.NewTTSBroadcaster(config)
+	if tts == nil {
+		t.Fatalf("TTSブロードキャスターの初期化に失敗しました")
+	}
+	tts.Start()
+
+	state := model.GameState{ID: "game"}
+	agent := model.Agent{Idx: 1, GameName: "Agent[01]", Profile: &model.Profile{Name: "ミナト", VoiceID: 3}}
+	tts.OnGameEvent(state, model.GameStartEvent{})
+	tts.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: agent, Text: "1 < 2"}})
+	tts.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: model.Agent{Idx: 2, GameName: "Agent[02]"}, Text: "no profile"}})
+
+	dir := filepath.Join(config.TTSBroadcaster.SegmentDir, "game")
+	master, err := os.ReadFile(filepath.Join(dir, "master.m3u8"))
+	if err != nil {
+		t.Fatalf("マスタープレイリストの読み込みに失敗しました: %v", err)
+	}
+	if !strings.Contains(string(master), `TYPE=SUBTITLES`) || !strings.Contains(string(master), `URI="subtitles.m3u8"`) || !strings.Contains(string(master), "playlist.m3u8") {
+		t.Errorf("マスタープレイリストの内容が不正です: %s", master)
+	}
+	subtitles, err := os.ReadFile(filepath.Join(dir, "subtitles.m3u8"))
+	if err != nil {
+		t.Fatalf("字幕プレイリストの読み込みに失敗しました: %v", err)
+	}
+	if !strings.Contains(string(subtitles), "segment_1.vtt") {
+		t.Fatalf("字幕プレイリストの内容が不正です: %s", subtitles)
+	}
+	cue, err := os.ReadFile(filepath.Join(dir, "segment_1.vtt"))
+	if err != nil {
+		t.Fatalf("字幕セグメントの読み込みに失敗しました: %v", err)
+	}
+	if !strings.HasPrefix(string(cue), "WEBVTT") || !strings.Contains(string(cue), "00:00:00.010 --> 00:00:00.020\n<v ミナト>ミナト: 1 &lt; 2</v>") {
+		t.Errorf("字幕セグメントの内容が不正です: %s", cue)
+	}
+	if cue, err := os.ReadFile(filepath.Join(dir, "segment_2.vtt")); err != nil || !strings.Contains(string(cue), "<v Agent[02]>Agent[02]: no profile</v>") {
+		t.Errorf("プロフィールのないエージェントの字幕が不正です: %s %v", cue, err)
+	}
+}
+
+func TestTTSSubtitleFailure(t *testing.T) {
+	config := newTestTTSConfig(t)
+	tts := service.NewTTSBroadcaster(config)
+	if tts == nil {
+		t.Fatalf("TTSブロードキャスターの初期化に失敗しました")
+	}
+	tts.Start()
+
+	state := model.GameState{ID: "game"}
+	tts.OnGameEvent(state, model.GameStartEvent{})
+	dir := filepath.Join(config.TTSBroadcaster.SegmentDir, "game")
+	if err := os.MkdirAll(filepath.Join(dir, "segment_1.vtt"), 0755); err != nil {
+		t.Fatalf("ディレクトリの作成に失敗しました: %v", err)
+	}
+	tts.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: model.Agent{Idx: 1, GameName: "Agent[01]"}, Text: "hello"}})
+
+	playlist, err := os.ReadFile(filepath.Join(dir, "playlist.m3u8"))
+	if err != nil {
+		t.Fatalf("プレイリストの読み込みに失敗しました: %v", err)
+	}
+	subtitles, err := os.ReadFile(filepath.Join(dir, "subtitles.m3u8"))
+	if err != nil {
+		t.Fatalf("字幕プレイリストの読み込みに失敗しました: %v", err)
+	}
+	if count := strings.Count(string(playlist), "#EXTINF"); count == 0 || strings.Count(string(subtitles), "#EXTINF") != count {
+		t.Errorf("字幕プレイリストのセグメント数が音声と一致しません: %s %s", playlist, subtitles)
+	}
+	if !strings.Contains(string(subtitles), "empty.vtt") {
+		t.Errorf("書き込みに失敗した字幕セグメントが空の字幕に置き換えられていません: %s", subtitles)
+	}
+	if empty, err := os.ReadFile(filepath.Join(dir, "empty.vtt")); err != nil || !strings.HasPrefix(string(empty), "WEBVTT") {
+		t.Errorf("空の字幕セグメントが不正です: %s %v", empty, err)
+	}
+}
+
+func TestTTSBlindWhisper(t *testing.T) {
+	config := newTestTTSConfig(t)
+	config.RealtimeBroadcaster.Blind = true
+	tts := service.NewTTSBroadcaster(config)
+	if tts == nil {
+		t.Fatalf("TTSブロードキャスターの初期化に失敗しました")
+	}
+	tts.Start()
+
+	state := model.GameState{ID: "game"}
+	agent := model.Agent{Idx: 1, GameName: "Agent[01]"}
+	tts.OnGameEvent(state, model.GameStartEvent{})
+	tts.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: agent, Text: "secret"}, Whisper: true})
+	tts.OnGameEvent(state, model.TalkEvent{Talk: model.Talk{Agent: agent, Text: "hello"}})
+
+	playlist, err := os.ReadFile(filepath.Join(config.TTSBroadcaster.SegmentDir, "game", "playlist.m3u8"))
+	if err != nil {
+		t.Fatalf("プレイリストの読み込みに失敗しました: %v", err)
+	}
+	if strings.Count(string(playlist), "#EXTINF") != 2 {
+		t.Errorf("囁きが読み上げられています: %s", playlist)
+	}
+}
+
+func newTestTTSConfig(t *testing.T) model.Config {
+	bin := t.TempDir()
+	scripts := map[string]string{
+		"ffmpeg":  "#!/bin/sh\nfor last; do :; done\necho segment > \"$last\"\n",
+		"ffprobe": "#!/bin/sh\necho 0.01\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatalf("スクリプトの作成に失敗しました: %v", err)
+		}
+	}
+
+	config := model.Config{}
+	config.TTSBroadcaster.Engine = service.TE_STUB
+	config.TTSBroadcaster.TargetDuration = time.Second
+	config.TTSBroadcaster.Timeout = time.Second
+	config.TTSBroadcaster.SegmentDir = t.TempDir()
+	config.TTSBroadcaster.TempDir = t.TempDir()
+	config.TTSBroadcaster.FfmpegPath = filepath.Join(bin, "ffmpeg")
+	config.TTSBroadcaster.FfprobePath = filepath.Join(bin, "ffprobe")
+	return config
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/core"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/gorilla/websocket"
+)
+
+func TestWaitingRoomPolicies(t *testing.T) {
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, err := upgrader.Upgrade(w, r, nil); err == nil {
+			conns <- conn
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	add := func(t *testing.T, wr *core.WaitingRoom, team string, count int) {
+		for range count {
+			client, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				t.Fatalf("接続に失敗しました: %v", err)
+			}
+			t.Cleanup(func() { client.Close() })
+			wr.AddConnection(team, model.Connection{TeamName: team, Conn: <-conns, ConnectedAt: time.Now()})
+		}
+	}
+	newWaitingRoom := func(matching model.MatchingConfig) *core.WaitingRoom {
+		config := model.Config{}
+		config.Game.AgentCount = 5
+		config.Matching = matching
+		return core.NewWaitingRoom(config)
+	}
+	seats := func(connections []model.Connection) map[string]int {
+		seats := make(map[string]int)
+		for _, conn := range connections {
+			seats[conn.TeamName]++
+		}
+		return seats
+	}
+
+	t.Run("self", func(t *testing.T) {
+		wr := newWaitingRoom(model.MatchingConfig{Policy: string(model.MP_SELF)})
+		add(t, wr, "alpha", 4)
+		if _, err := wr.GetConnections(); err == nil {
+			t.Errorf("接続が不足しているにもかかわらずマッチが成立しました")
+		}
+		add(t, wr, "alpha", 1)
+		if connections, err := wr.GetConnections(); err != nil || seats(connections)["alpha"] != 5 {
+			t.Errorf("同じチームのマッチが成立しません: %v %v", seats(connections), err)
+		}
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		wr := newWaitingRoom(model.MatchingConfig{Policy: string(model.MP_MIXED), MaxSeatsPerTeam: 2})
+		add(t, wr, "alpha", 3)
+		add(t, wr, "bravo", 3)
+		if _, err := wr.GetConnections(); err == nil {
+			t.Errorf("1チームあたりの席数の上限を超えてマッチが成立しました")
+		}
+		add(t, wr, "charlie", 1)
+		connections, err := wr.GetConnections()
+		if err != nil || len(connections) != 5 {
+			t.Fatalf("マッチが成立しません: %v", err)
+		}
+		for team, count := range seats(connections) {
+			if count > 2 {
+				t.Errorf("1チームあたりの席数の上限を超えています: %s=%d", team, count)
+			}
+		}
+	})
+
+	t.Run("self_fallback", func(t *testing.T) {
+		wr := newWaitingRoom(model.MatchingConfig{Policy: string(model.MP_SELF_FALLBACK), FallbackTimeout: 200 * time.Millisecond})
+		add(t, wr, "alpha", 5)
+		if _, err := wr.GetConnections(); err == nil {
+			t.Errorf("待機時間の経過前に同じチームのマッチが成立しました")
+		}
+		time.Sleep(250 * time.Millisecond)
+		if connections, err := wr.GetConnections(); err != nil || seats(connections)["alpha"] != 5 {
+			t.Errorf("待機時間の経過後に同じチームのマッチが成立しません: %v %v", seats(connections), err)
+		}
+
+		add(t, wr, "alpha", 3)
+		add(t, wr, "bravo", 2)
+		if _, err := wr.GetConnections(); err == nil {
+			t.Errorf("待機時間の経過前に同じチームで席を埋めたマッチが成立しました")
+		}
+		time.Sleep(250 * time.Millisecond)
+		if connections, err := wr.GetConnections(); err != nil || seats(connections)["alpha"] != 3 || seats(connections)["bravo"] != 2 {
+			t.Errorf("待機時間の経過後に待機中のチームで席を埋めたマッチが成立しません: %v %v", seats(connections), err)
+		}
+	})
+
+	t.Run("house", func(t *testing.T) {
+		wr := newWaitingRoom(model.MatchingConfig{Policy: string(model.MP_HOUSE), HouseTeams: []string{"house"}})
+		add(t, wr, "house", 3)
+		add(t, wr, "alpha", 2)
+		if _, err := wr.GetConnections(); err == nil {
+			t.Errorf("ハウスボットが不足しているにもかかわらずマッチが成立しました")
+		}
+		add(t, wr, "house", 1)
+		connections, err := wr.GetConnections()
+		if err != nil || seats(connections)["alpha"] != 1 || seats(connections)["house"] != 4 {
+			t.Errorf("ハウスボットで埋めたマッチが成立しません: %v %v", seats(connections), err)
+		}
+	})
+}
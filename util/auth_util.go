@@ -60,3 +60,30 @@ func IsValidReceiver(secret string, tokenString string) bool {
 	}
 	return false
 }
+
+func IsValidAdmin(secret string, tokenString string) bool {
+	slog.Info("管理者トークンを検証します", "token", tokenString)
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		if _, exists := token.Method.(*jwt.SigningMethodHMAC); !exists {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		slog.Warn("トークンの検証に失敗しました", "error", err)
+		return false
+	}
+	if !token.Valid {
+		slog.Warn("トークンの有効期限が切れています")
+		return false
+	}
+	if claims, exists := token.Claims.(jwt.MapClaims); exists {
+		if claims["role"] == "ADMIN" {
+			slog.Info("トークンが有効です")
+			return true
+		}
+	} else {
+		slog.Warn("クレームの取得に失敗しました")
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"maps"
 	"math/rand/v2"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -46,6 +47,26 @@ func CalcHasErrorAgents(agents []*model.Agent) int {
 	return count
 }
 
+func GetTeamNames(agents []*model.Agent) []string {
+	teams := make([]string, 0)
+	for _, a := range agents {
+		if !slices.Contains(teams, a.TeamName) {
+			teams = append(teams, a.TeamName)
+		}
+	}
+	return teams
+}
+
+func GetErrorTeamNames(agents []*model.Agent) []string {
+	teams := make([]string, 0)
+	for _, a := range agents {
+		if a.HasError && !slices.Contains(teams, a.TeamName) {
+			teams = append(teams, a.TeamName)
+		}
+	}
+	return teams
+}
+
 func GetRoleMap(agents []*model.Agent) map[model.Agent]model.Role {
 	roleMap := make(map[model.Agent]model.Role)
 	for _, a := range agents {
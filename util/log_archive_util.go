@@ -0,0 +1,122 @@
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const GzipExt = ".gz"
+
+const (
+	ArchivePrefixGame = "game"
+	ArchivePrefixJSON = "json"
+)
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+func OpenLogFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, GzipExt) {
+		return file, nil
+	}
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return gzipReadCloser{Reader: reader, file: file}, nil
+}
+
+func WalkLogFiles(dir string, archiveDir string, prefix string, ext string, fn func(name string, r io.Reader) error) error {
+	paths := []string{}
+	for _, pattern := range []string{"*" + ext, "*" + ext + GzipExt} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	for _, path := range paths {
+		if err := walkLogFile(path, fn); err != nil {
+			return err
+		}
+	}
+	if archiveDir == "" {
+		return nil
+	}
+	archives, err := filepath.Glob(filepath.Join(archiveDir, prefix+"-*.tar"))
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		if err := walkArchive(archive, ext, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkLogFile(path string, fn func(name string, r io.Reader) error) error {
+	reader, err := OpenLogFile(path)
+	if err != nil {
+		slog.Warn("ログファイルの読み込みに失敗しました", "path", path, "error", err)
+		return nil
+	}
+	defer reader.Close()
+	return fn(path, reader)
+}
+
+func walkArchive(path string, ext string, fn func(name string, r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		slog.Warn("アーカイブの読み込みに失敗しました", "path", path, "error", err)
+		return nil
+	}
+	defer file.Close()
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			slog.Warn("アーカイブの読み込みに失敗しました", "path", path, "error", err)
+			return nil
+		}
+		name := path + "/" + header.Name
+		switch {
+		case strings.HasSuffix(header.Name, ext):
+			if err := fn(name, tr); err != nil {
+				return err
+			}
+		case strings.HasSuffix(header.Name, ext+GzipExt):
+			reader, err := gzip.NewReader(tr)
+			if err != nil {
+				slog.Warn("アーカイブ内のファイルの展開に失敗しました", "name", name, "error", err)
+				continue
+			}
+			err = fn(name, reader)
+			reader.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}